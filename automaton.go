@@ -0,0 +1,73 @@
+package marker
+
+type suffixAutomatonState struct {
+	next   map[rune]int
+	link   int
+	length int
+}
+
+// suffixAutomaton recognizes all substrings of the runes it is built from, it is built in linear time
+type suffixAutomaton []suffixAutomatonState
+
+func newSuffixAutomaton(runes []rune) suffixAutomaton {
+	sa := suffixAutomaton{{next: map[rune]int{}, link: -1}}
+	last := 0
+	for _, r := range runes {
+		current := len(sa)
+		sa = append(sa, suffixAutomatonState{next: map[rune]int{}, length: sa[last].length + 1})
+		p := last
+		for p != -1 {
+			if _, ok := sa[p].next[r]; ok {
+				break
+			}
+			sa[p].next[r] = current
+			p = sa[p].link
+		}
+		switch {
+		case p == -1:
+			sa[current].link = 0
+		case sa[p].length+1 == sa[sa[p].next[r]].length:
+			sa[current].link = sa[p].next[r]
+		default:
+			q := sa[p].next[r]
+			clone := len(sa)
+			next := make(map[rune]int, len(sa[q].next))
+			for k, v := range sa[q].next {
+				next[k] = v
+			}
+			sa = append(sa, suffixAutomatonState{next: next, link: sa[q].link, length: sa[p].length + 1})
+			for p != -1 && sa[p].next[r] == q {
+				sa[p].next[r] = clone
+				p = sa[p].link
+			}
+			sa[q].link = clone
+			sa[current].link = clone
+		}
+		last = current
+	}
+	return sa
+}
+
+// longestSuffixes returns, for each position of given runes, the length of the longest substring ending there which is
+// recognized by the automaton
+func (sa suffixAutomaton) longestSuffixes(runes []rune) []int {
+	lengths := make([]int, len(runes))
+	state, length := 0, 0
+	for i, r := range runes {
+		for state != 0 {
+			if _, ok := sa[state].next[r]; ok {
+				break
+			}
+			state = sa[state].link
+			length = sa[state].length
+		}
+		if next, ok := sa[state].next[r]; ok {
+			state = next
+			length++
+		} else {
+			state, length = 0, 0
+		}
+		lengths[i] = length
+	}
+	return lengths
+}
@@ -31,3 +31,46 @@ func (m *MarkBuilder) MarkMany(c *color.Color, matcherFuncs ...MatcherFunc) *Mar
 func (m *MarkBuilder) Build() string {
 	return m.str
 }
+
+// Pipeline is a fluent way to compose a MatcherFunc from other MatcherFuncs and wrappers
+type Pipeline struct {
+	matcherFuncs    []MatcherFunc
+	limit           int
+	caseInsensitive bool
+}
+
+// NewPipeline creates an empty Pipeline
+func NewPipeline() *Pipeline {
+	return &Pipeline{limit: -1}
+}
+
+// Add appends a MatcherFunc to the Pipeline, added MatcherFuncs are combined with MatchAny in the order they are added
+func (p *Pipeline) Add(matcherFunc MatcherFunc) *Pipeline {
+	p.matcherFuncs = append(p.matcherFuncs, matcherFunc)
+	return p
+}
+
+// Limit limits the built MatcherFunc to the first n patterns
+func (p *Pipeline) Limit(n int) *Pipeline {
+	p.limit = n
+	return p
+}
+
+// CaseInsensitive makes the built MatcherFunc apply the added MatcherFuncs on the lower-cased string
+func (p *Pipeline) CaseInsensitive() *Pipeline {
+	p.caseInsensitive = true
+	return p
+}
+
+// Build returns the composed MatcherFunc. Regardless of the call order, the added MatcherFuncs are combined first,
+// then made case insensitive and finally limited.
+func (p *Pipeline) Build() MatcherFunc {
+	matcherFunc := MatchAny(p.matcherFuncs...)
+	if p.caseInsensitive {
+		matcherFunc = CaseInsensitive(matcherFunc)
+	}
+	if p.limit >= 0 {
+		matcherFunc = Limit(matcherFunc, p.limit)
+	}
+	return matcherFunc
+}
@@ -47,3 +47,25 @@ func Benchmark_Builder(b *testing.B) {
 			Build()
 	}
 }
+
+func Test_Pipeline(t *testing.T) {
+	str := "Mail ME at me@skydome.io on Monday, not me@example.com on Friday"
+
+	pipelineMatcher := NewPipeline().
+		Limit(3).
+		Add(MatchEmail()).
+		Add(MatchDaysOfWeek()).
+		Add(MatchAll("me")).
+		CaseInsensitive().
+		Build()
+
+	nestedMatcher := Limit(CaseInsensitive(MatchAny(MatchEmail(), MatchDaysOfWeek(), MatchAll("me"))), 3)
+
+	expectedMatch := Match{
+		Template: "Mail %s at %s on %s, not me@example.com on Friday",
+		Patterns: []string{"ME", "me@skydome.io", "Monday"},
+	}
+
+	assert.Equal(t, expectedMatch, pipelineMatcher(str))
+	assert.Equal(t, nestedMatcher(str), pipelineMatcher(str))
+}
@@ -1,6 +1,7 @@
 package marker
 
 import (
+	"bytes"
 	"io"
 	"os"
 
@@ -46,9 +47,52 @@ func (s *WriteMarker) AddRules(rules []MarkRule) {
 
 // Write marks the text with specified rules and writes the output to specifed out
 func (s WriteMarker) Write(p []byte) (n int, err error) {
-	marked := string(p)
-	for _, rule := range s.rules {
-		marked = Mark(marked, rule.Matcher, rule.Color)
+	return s.out.Write([]byte(markRules(string(p), s.rules)))
+}
+
+// HighlightWriter buffers the written text until a newline and writes each line marked with specified rules
+type HighlightWriter struct {
+	rules  []MarkRule
+	out    io.Writer
+	buffer []byte
+}
+
+// NewHighlightWriter creates a HighlightWriter that writes out marked lines to the given io.Writer
+func NewHighlightWriter(writer io.Writer, rules []MarkRule) *HighlightWriter {
+	return &HighlightWriter{rules: rules, out: writer}
+}
+
+// Write buffers p and writes out every completed line marked with specified rules
+func (h *HighlightWriter) Write(p []byte) (n int, err error) {
+	h.buffer = append(h.buffer, p...)
+	for {
+		newline := bytes.IndexByte(h.buffer, '\n')
+		if newline < 0 {
+			return len(p), nil
+		}
+		line := string(h.buffer[:newline+1])
+		h.buffer = h.buffer[newline+1:]
+		if _, err := io.WriteString(h.out, markRules(line, h.rules)); err != nil {
+			return len(p), err
+		}
+	}
+}
+
+// Close writes out the remaining buffered text which is not terminated by a newline. It does not close the underlying
+// io.Writer.
+func (h *HighlightWriter) Close() error {
+	if len(h.buffer) == 0 {
+		return nil
+	}
+	line := string(h.buffer)
+	h.buffer = nil
+	_, err := io.WriteString(h.out, markRules(line, h.rules))
+	return err
+}
+
+func markRules(str string, rules []MarkRule) string {
+	for _, rule := range rules {
+		str = Mark(str, rule.Matcher, rule.Color)
 	}
-	return s.out.Write([]byte(marked))
+	return str
 }
@@ -1,6 +1,7 @@
 package marker
 
 import (
+	"bytes"
 	"fmt"
 	"log"
 	"os"
@@ -59,3 +60,36 @@ func Test_Write(t *testing.T) {
 	assert.Equal(t, expectedLog, mockOut.actualLog)
 
 }
+
+func Test_HighlightWriter(t *testing.T) {
+	redFg := color.New(color.FgRed)
+	redFg.EnableColor()
+	red := redFg.SprintFunc()
+	blueFg := color.New(color.FgBlue)
+	blueFg.EnableColor()
+	blue := blueFg.SprintFunc()
+
+	rules := []MarkRule{
+		{Matcher: MatchAll("skydome"), Color: redFg},
+		{Matcher: MatchAll("data"), Color: blueFg},
+	}
+	input := "best data company is skydome\nskydome loves data\nno newline at the end: data"
+	expected := fmt.Sprintf("best %s company is %s\n%s loves %s\nno newline at the end: %s",
+		blue("data"), red("skydome"), red("skydome"), blue("data"), blue("data"))
+
+	for _, chunkSize := range []int{1, 3, 7, 16, len(input)} {
+		out := &bytes.Buffer{}
+		highlightWriter := NewHighlightWriter(out, rules)
+		for i := 0; i < len(input); i += chunkSize {
+			end := i + chunkSize
+			if end > len(input) {
+				end = len(input)
+			}
+			n, err := highlightWriter.Write([]byte(input[i:end]))
+			assert.NoError(t, err)
+			assert.Equal(t, end-i, n)
+		}
+		assert.NoError(t, highlightWriter.Close())
+		assert.Equal(t, expected, out.String())
+	}
+}
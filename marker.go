@@ -1,7 +1,13 @@
 package marker
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/fatih/color"
 )
@@ -23,6 +29,259 @@ func MarkMany(str string, c *color.Color, matcherFuncs ...MatcherFunc) string {
 	return str
 }
 
+// Scan calls fn with each pattern returned from MatcherFunc and its byte offsets in given string, in order.
+// Scanning stops early when fn returns false.
+func Scan(str string, matcherFunc MatcherFunc, fn func(pattern string, start, end int) bool) {
+	match := matcherFunc(str)
+	_, indexes, patternIndexes := parseTemplate(match)
+	for i, index := range indexes {
+		if !fn(match.Patterns[patternIndexes[i]], match.Offset+index[0], match.Offset+index[1]) {
+			return
+		}
+	}
+}
+
+// MatchAt applies MatcherFunc on given string, which starts at given offset of a larger string like the visible part of
+// a document, and returns the Match with its Offset shifted by startOffset, so its Indexes are offsets in the larger
+// string. The Match is meant to be used as is: MatcherFuncs combining or filtering it work on the offsets in given
+// string and drop the Offset.
+func MatchAt(str string, startOffset int, matcherFunc MatcherFunc) Match {
+	match := matcherFunc(str)
+	match.Offset += startOffset
+	return match
+}
+
+// Transform returns the string marked by the Match of MatcherFunc on given string, which is given string unless
+// MatcherFunc changes it, with each pattern replaced by the result of repl
+func Transform(str string, matcherFunc MatcherFunc, repl func(string) string) string {
+	marked, indexes, _ := parseTemplate(matcherFunc(str))
+	var transformed strings.Builder
+	last := 0
+	for _, index := range indexes {
+		transformed.WriteString(marked[last:index[0]])
+		transformed.WriteString(repl(marked[index[0]:index[1]]))
+		last = index[1]
+	}
+	transformed.WriteString(marked[last:])
+	return transformed.String()
+}
+
+// ReplaceAllReport returns given string with each pattern returned from MatcherFunc replaced by repl and the number of
+// replaced patterns
+func ReplaceAllReport(str string, matcherFunc MatcherFunc, repl string) (result string, count int) {
+	match := matcherFunc(str)
+	return match.Map(func(string) string { return repl }).Render(), len(match.Patterns)
+}
+
+// Index returns the byte offsets of each distinct pattern returned from MatcherFunc in given string, in order
+func Index(str string, matcherFunc MatcherFunc) map[string][][2]int {
+	return index(str, matcherFunc, func(pattern string) string { return pattern })
+}
+
+// IndexFold works like Index but groups patterns which are equal under Unicode case-folding, keyed by their lower case
+// form
+func IndexFold(str string, matcherFunc MatcherFunc) map[string][][2]int {
+	return index(str, matcherFunc, strings.ToLower)
+}
+
+func index(str string, matcherFunc MatcherFunc, key func(string) string) map[string][][2]int {
+	offsets := make(map[string][][2]int)
+	Scan(str, matcherFunc, func(pattern string, start, end int) bool {
+		k := key(pattern)
+		offsets[k] = append(offsets[k], [2]int{start, end})
+		return true
+	})
+	return offsets
+}
+
+// ContextLine is a line of a ContextBlock with its number counting from 1
+type ContextLine struct {
+	Number  int
+	Text    string
+	Matched bool
+}
+
+// ContextBlock is a run of consecutive lines containing patterns with their surrounding lines
+type ContextBlock struct {
+	Lines []ContextLine
+}
+
+// GrepContext returns the lines of given string containing patterns returned from MatcherFunc with given number of lines
+// before and after each, like grep -B and -A. Lines containing a pattern are marked as matched and the lines of a pattern
+// spanning line breaks are all matched. Overlapping or adjacent blocks are merged into one.
+func GrepContext(str string, matcherFunc MatcherFunc, before, after int) []ContextBlock {
+	lines := strings.SplitAfter(str, "\n")
+	if len(lines) > 1 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	lineStarts := make([]int, len(lines))
+	for i := 1; i < len(lines); i++ {
+		lineStarts[i] = lineStarts[i-1] + len(lines[i-1])
+	}
+	lineOf := func(offset int) int {
+		return sort.Search(len(lineStarts), func(i int) bool { return lineStarts[i] > offset }) - 1
+	}
+
+	matched := make([]bool, len(lines))
+	_, indexes, _ := parseTemplate(matcherFunc(str))
+	for _, index := range indexes {
+		last := lineOf(max(index[0], index[1]-1))
+		for line := lineOf(index[0]); line <= last; line++ {
+			matched[line] = true
+		}
+	}
+
+	var blocks []ContextBlock
+	end := -1
+	for line := range lines {
+		if !matched[line] {
+			continue
+		}
+		start := max(line-before, 0)
+		if len(blocks) == 0 || start > end {
+			blocks = append(blocks, ContextBlock{})
+			end = start
+		}
+		block := &blocks[len(blocks)-1]
+		for ; end <= min(line+after, len(lines)-1); end++ {
+			text := strings.TrimSuffix(lines[end], "\n")
+			block.Lines = append(block.Lines, ContextLine{Number: end + 1, Text: text, Matched: matched[end]})
+		}
+	}
+	return blocks
+}
+
+// Stats contains measurements of a MatcherFunc applied on a string
+type Stats struct {
+	Elapsed      time.Duration
+	InputLength  int
+	MatchCount   int
+	MatchedBytes int
+}
+
+// MatchWithStats applies MatcherFunc on given string and returns the Match with the time it took, the length of the
+// string in bytes, the number of patterns and their total length in bytes
+func MatchWithStats(str string, matcherFunc MatcherFunc) (Match, Stats) {
+	start := time.Now()
+	match := matcherFunc(str)
+	stats := Stats{Elapsed: time.Since(start), InputLength: len(str), MatchCount: len(match.Patterns)}
+	for _, pattern := range match.Patterns {
+		stats.MatchedBytes += len(pattern)
+	}
+	return match, stats
+}
+
+// MatchWithProgress applies MatcherFunc on given string and calls onProgress with the number of bytes processed so far
+// and the length of the string, once with 0 before matching and once with the length after matching. The string is
+// matched as a whole, so the Match is the one MatcherFunc returns. See MatchLinesWithProgress for periodic reports.
+func MatchWithProgress(str string, matcherFunc MatcherFunc, onProgress func(bytesProcessed, total int)) Match {
+	onProgress(0, len(str))
+	match := matcherFunc(str)
+	onProgress(len(str), len(str))
+	return match
+}
+
+// MatchLinesWithProgress works like MatchWithProgress but matches given string in chunks of about chunkSize bytes ending
+// at line breaks and calls onProgress after each chunk, so progress is reported periodically on large strings. It is
+// meant for line-local MatcherFuncs whose patterns neither span line breaks nor depend on other lines; MatcherFuncs like
+// Limit or MatchN, which count patterns, or multi-line regexps find different patterns when applied on chunks.
+func MatchLinesWithProgress(str string, matcherFunc MatcherFunc, chunkSize int, onProgress func(bytesProcessed, total int)) Match {
+	onProgress(0, len(str))
+	if str == "" {
+		return matcherFunc(str)
+	}
+	var all []capture
+	for start := 0; start < len(str); {
+		end := len(str)
+		if end-start > chunkSize {
+			end = start + max(chunkSize, 1)
+			if lineBreak := strings.IndexByte(str[end-1:], '\n'); lineBreak >= 0 {
+				end += lineBreak
+			} else {
+				end = len(str)
+			}
+		}
+		match := matcherFunc(str[start:end])
+		_, chunkCaptures := captures(&match)
+		for _, c := range chunkCaptures {
+			c.index = []int{start + c.index[0], start + c.index[1]}
+			all = append(all, c)
+		}
+		onProgress(end, len(str))
+		start = end
+	}
+	return buildMatch(str, all)
+}
+
+// MatchFile applies MatcherFunc on the file at given path in windows of given number of bytes, each window starting
+// overlap bytes before the end of the previous one, and sends a Match for each window on the returned Match channel.
+// The Match of a window covers the bytes following the ones covered by the previous Match and its patterns are the ones
+// starting there, so a pattern straddling two windows is found in the overlap and sent once, and rendering the Matches
+// in order yields the file. Patterns are assumed to be at most overlap bytes long; longer ones may be cut at a window
+// end. The Match channel is closed when the file is read, a read fails or done is closed. The error channel then
+// receives the read error, if any, and is closed, so a truncated read can be told from a complete one.
+func MatchFile(path string, matcherFunc MatcherFunc, window, overlap int,
+	done <-chan struct{}) (<-chan Match, <-chan error, error) {
+	if overlap < 0 || window <= overlap {
+		return nil, nil, errors.New("marker: window must be larger than overlap")
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	matches := make(chan Match)
+	errs := make(chan error, 1)
+	go func() {
+		defer close(errs)
+		defer close(matches)
+		defer file.Close()
+		chunk := make([]byte, 0, window)
+		offset, from := 0, 0
+		for {
+			n, err := io.ReadFull(file, chunk[len(chunk):window])
+			chunk = chunk[:len(chunk)+n]
+			limit := offset + len(chunk) - overlap
+			if err != nil {
+				limit = offset + len(chunk)
+			}
+			if match, end := matchWindow(string(chunk), matcherFunc, from-offset, limit-offset); end > from-offset {
+				select {
+				case matches <- match:
+				case <-done:
+					return
+				}
+				from = offset + end
+			}
+			if err != nil {
+				if err != io.EOF && err != io.ErrUnexpectedEOF {
+					errs <- err
+				}
+				return
+			}
+			chunk = chunk[:copy(chunk, chunk[len(chunk)-overlap:])]
+			offset = limit
+		}
+	}()
+	return matches, errs, nil
+}
+
+// matchWindow applies matcherFunc on given window and returns the Match of the window from given offset, ending at given
+// limit or at the end of the last pattern starting before it, with the offset where it ends
+func matchWindow(window string, matcherFunc MatcherFunc, from, limit int) (Match, int) {
+	match := matcherFunc(window)
+	_, all := captures(&match)
+	end := max(from, limit)
+	var kept []capture
+	for _, c := range all {
+		if c.index[0] >= from && c.index[0] < limit {
+			end = max(end, c.index[1])
+			c.index = []int{c.index[0] - from, c.index[1] - from}
+			kept = append(kept, c)
+		}
+	}
+	return buildMatch(window[from:end], kept), end
+}
+
 func colorizeStrings(strs []string, c *color.Color) {
 	for i := range strs {
 		strs[i] = c.Sprintf("%s", strs[i])
@@ -2,7 +2,10 @@ package marker
 
 import (
 	"fmt"
+	"io/ioutil"
+	"os"
 	"regexp"
+	"strings"
 	"testing"
 
 	"github.com/fatih/color"
@@ -125,3 +128,236 @@ func Benchmark_MarkMany(b *testing.B) {
 		MarkMany(data.text, data.color, data.matchers...)
 	}
 }
+
+func Test_Scan(t *testing.T) {
+	type scanned struct {
+		pattern    string
+		start, end int
+	}
+	str := "Skydome is a data company. Skydome loves data."
+
+	var actual []scanned
+	Scan(str, MatchMultiple([]string{"Skydome", "data"}), func(pattern string, start, end int) bool {
+		actual = append(actual, scanned{pattern, start, end})
+		return true
+	})
+
+	expected := []scanned{{"Skydome", 0, 7}, {"data", 13, 17}, {"Skydome", 27, 34}, {"data", 41, 45}}
+	assert.Equal(t, expected, actual)
+	for _, s := range actual {
+		assert.Equal(t, s.pattern, str[s.start:s.end])
+	}
+
+	actual = nil
+	Scan(str, MatchAll("Skydome"), func(pattern string, start, end int) bool {
+		actual = append(actual, scanned{pattern, start, end})
+		return false
+	})
+
+	assert.Equal(t, []scanned{{"Skydome", 0, 7}}, actual)
+
+	reordered := func(string) Match { return Match{Template: "%[2]s-%[1]s %[1]s", Patterns: []string{"x", "yy"}} }
+	actual = nil
+	Scan("", reordered, func(pattern string, start, end int) bool {
+		actual = append(actual, scanned{pattern, start, end})
+		return true
+	})
+
+	assert.Equal(t, []scanned{{"yy", 0, 2}, {"x", 3, 4}, {"x", 5, 6}}, actual)
+	assert.Equal(t, map[string][][2]int{"yy": {{0, 2}}, "x": {{3, 4}, {5, 6}}}, Index("", reordered))
+}
+
+func Test_MatchAt(t *testing.T) {
+	document := "Skydome data, more Skydome data"
+	start := 14
+
+	actualMatch := MatchAt(document[start:], start, MatchMultiple([]string{"Skydome", "data"}))
+	assert.Equal(t, start, actualMatch.Offset)
+	assert.Equal(t, "more %s %s", actualMatch.Template)
+	assert.Equal(t, [][]int{{19, 26}, {27, 31}}, actualMatch.Indexes())
+	for i, index := range actualMatch.Indexes() {
+		assert.Equal(t, actualMatch.Patterns[i], document[index[0]:index[1]])
+	}
+	assert.Equal(t, document[start:], actualMatch.Render())
+
+	actualMatch = MatchAt(document, 0, MatchAll("data"))
+	assert.Equal(t, MatchAll("data")(document), actualMatch)
+
+	shifted := func(str string) Match { return MatchAt(str, 100, MatchAll("data")) }
+	assert.Equal(t, MatchAroundIndex(MatchAll("data"), 30)(document), MatchAroundIndex(shifted, 30)(document))
+	assert.Equal(t, GrepContext(document, MatchAll("data"), 0, 0), GrepContext(document, shifted, 0, 0))
+	assert.Equal(t, MatchAll("data")(document), Limit(shifted, 2)(document))
+}
+
+func Test_Transform(t *testing.T) {
+	str := "Meet on Monday or tuesday, not on Sunday."
+	abbreviate := func(day string) string { return day[:3] }
+
+	actual := Transform(str, MatchDaysOfWeek(), abbreviate)
+	assert.Equal(t, "Meet on Mon or tue, not on Sun.", actual)
+
+	actual = Transform(str, MatchAll("Friday"), abbreviate)
+	assert.Equal(t, str, actual)
+
+	actual = Transform("no no no yes no", CollapseAdjacent(MatchAll("no")), strings.ToUpper)
+	assert.Equal(t, "NO yes NO", actual)
+}
+
+func Test_Index(t *testing.T) {
+	str := "Skydome data, skydome. Skydome data, SKYDOME"
+	matcher := MatchRegexp(regexp.MustCompile(`(?i)skydome|data`))
+
+	expected := map[string][][2]int{
+		"Skydome": {{0, 7}, {23, 30}},
+		"skydome": {{14, 21}},
+		"SKYDOME": {{37, 44}},
+		"data":    {{8, 12}, {31, 35}},
+	}
+	assert.Equal(t, expected, Index(str, matcher))
+
+	expected = map[string][][2]int{
+		"skydome": {{0, 7}, {14, 21}, {23, 30}, {37, 44}},
+		"data":    {{8, 12}, {31, 35}},
+	}
+	assert.Equal(t, expected, IndexFold(str, matcher))
+
+	assert.Empty(t, Index(str, MatchAll("marker")))
+}
+
+func Test_MatchWithStats(t *testing.T) {
+	str := "Skydome data, more skydome data"
+	matcher := MatchMultiple([]string{"Skydome", "data"})
+
+	actualMatch, stats := MatchWithStats(str, matcher)
+	assert.Equal(t, matcher(str), actualMatch)
+	assert.Equal(t, len(str), stats.InputLength)
+	assert.Equal(t, 3, stats.MatchCount)
+	assert.Equal(t, 15, stats.MatchedBytes)
+	assert.True(t, stats.Elapsed >= 0)
+
+	_, stats = MatchWithStats("", matcher)
+	assert.Equal(t, Stats{Elapsed: stats.Elapsed}, stats)
+}
+
+func Test_MatchFile(t *testing.T) {
+	file, err := ioutil.TempFile("", "marker")
+	assert.NoError(t, err)
+	defer os.Remove(file.Name())
+	content := "Skydome data more Skydome\nSkydome data"
+	_, err = file.WriteString(content)
+	assert.NoError(t, err)
+	assert.NoError(t, file.Close())
+
+	matches, errs, err := MatchFile(file.Name(), MatchAll("Skydome"), 16, 8, nil)
+	assert.NoError(t, err)
+
+	var patterns []string
+	var rendered strings.Builder
+	for match := range matches {
+		patterns = append(patterns, match.Patterns...)
+		rendered.WriteString(match.Render())
+	}
+	assert.Equal(t, []string{"Skydome", "Skydome", "Skydome"}, patterns)
+	assert.Equal(t, content, rendered.String())
+	assert.NoError(t, <-errs)
+
+	done := make(chan struct{})
+	matches, errs, err = MatchFile(file.Name(), MatchAll("Skydome"), 16, 8, done)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Skydome"}, (<-matches).Patterns)
+	close(done)
+	for range matches {
+	}
+	assert.NoError(t, <-errs)
+
+	dir, err := ioutil.TempDir("", "marker")
+	assert.NoError(t, err)
+	defer os.Remove(dir)
+	matches, errs, err = MatchFile(dir, MatchAll("Skydome"), 16, 8, nil)
+	assert.NoError(t, err)
+	for range matches {
+	}
+	assert.Error(t, <-errs)
+
+	_, _, err = MatchFile(file.Name(), MatchAll("Skydome"), 8, 8, nil)
+	assert.Error(t, err)
+
+	_, _, err = MatchFile(file.Name()+".missing", MatchAll("Skydome"), 16, 8, nil)
+	assert.Error(t, err)
+}
+
+func Test_MatchWithProgress(t *testing.T) {
+	str := strings.Repeat("Skydome data, more skydome\n", 100)
+	var reports [][2]int
+	onProgress := func(bytesProcessed, total int) { reports = append(reports, [2]int{bytesProcessed, total}) }
+
+	matcher := MatchMultiple([]string{"Skydome", "data"})
+	assert.Equal(t, matcher(str), MatchWithProgress(str, matcher, onProgress))
+	assert.Equal(t, [][2]int{{0, len(str)}, {len(str), len(str)}}, reports)
+
+	str = strings.Repeat("a1\na2\na3\na4\n", 100)
+	matcher = Limit(MatchRegexp(regexp.MustCompile(`a\d`)), 1)
+	reports = nil
+	assert.Equal(t, Match{Template: "%s" + str[2:], Patterns: []string{"a1"}}, MatchWithProgress(str, matcher, onProgress))
+	assert.Equal(t, [][2]int{{0, len(str)}, {len(str), len(str)}}, reports)
+}
+
+func Test_ReplaceAllReport(t *testing.T) {
+	str := "Monday, tuesday and Friday; not Sunday or saturday"
+
+	result, count := ReplaceAllReport(str, MatchDaysOfWeek(), "DAY")
+	assert.Equal(t, "DAY, DAY and DAY; not DAY or DAY", result)
+	assert.Equal(t, 5, count)
+
+	result, count = ReplaceAllReport(str, MatchAll("marker"), "DAY")
+	assert.Equal(t, str, result)
+	assert.Equal(t, 0, count)
+}
+
+func Test_GrepContext(t *testing.T) {
+	lines := []string{"one", "two", "three", "four", "five", "six", "seven", "eight", "nine", "ten"}
+	str := strings.Join(lines, "\n") + "\n"
+	contextLines := func(first, last int, matched ...int) []ContextLine {
+		var contextLines []ContextLine
+		for number := first; number <= last; number++ {
+			contextLines = append(contextLines, ContextLine{Number: number, Text: lines[number-1]})
+		}
+		for _, number := range matched {
+			contextLines[number-first].Matched = true
+		}
+		return contextLines
+	}
+
+	actual := GrepContext(str, MatchAll("five"), 2, 1)
+	assert.Equal(t, []ContextBlock{{Lines: contextLines(3, 6, 5)}}, actual)
+
+	actual = GrepContext(str, MatchMultiple([]string{"five", "eight"}), 2, 1)
+	assert.Equal(t, []ContextBlock{{Lines: contextLines(3, 9, 5, 8)}}, actual)
+
+	actual = GrepContext(str, MatchMultiple([]string{"one", "ten"}), 2, 1)
+	assert.Equal(t, []ContextBlock{{Lines: contextLines(1, 2, 1)}, {Lines: contextLines(8, 10, 10)}}, actual)
+
+	actual = GrepContext(str, MatchAll("nine\nten"), 0, 0)
+	assert.Equal(t, []ContextBlock{{Lines: contextLines(9, 10, 9, 10)}}, actual)
+
+	assert.Empty(t, GrepContext(str, MatchAll("eleven"), 2, 1))
+}
+
+func Test_MatchLinesWithProgress(t *testing.T) {
+	str := strings.Repeat("Skydome data, more skydome\n", 100)
+	matcher := MatchMultiple([]string{"Skydome", "data"})
+
+	var reports [][2]int
+	onProgress := func(bytesProcessed, total int) { reports = append(reports, [2]int{bytesProcessed, total}) }
+
+	assert.Equal(t, matcher(str), MatchLinesWithProgress(str, matcher, 1000, onProgress))
+	assert.Equal(t, [][2]int{{0, len(str)}, {1026, len(str)}, {2052, len(str)}, {len(str), len(str)}}, reports)
+
+	reports = nil
+	assert.Equal(t, matcher(str), MatchLinesWithProgress(str, matcher, len(str), onProgress))
+	assert.Equal(t, [][2]int{{0, len(str)}, {len(str), len(str)}}, reports)
+
+	reports = nil
+	assert.Equal(t, matcher(""), MatchLinesWithProgress("", matcher, 1000, onProgress))
+	assert.Equal(t, [][2]int{{0, 0}}, reports)
+}
@@ -0,0 +1,460 @@
+package marker
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Indexes returns the start and end byte offsets of each pattern in the marked string, shifted by the Offset of the Match
+func (m Match) Indexes() [][]int {
+	_, indexes, _ := parseTemplate(m)
+	for _, index := range indexes {
+		index[0] += m.Offset
+		index[1] += m.Offset
+	}
+	return indexes
+}
+
+// Segment is a part of a marked string which is either a pattern or literal text
+type Segment struct {
+	Text    string
+	IsMatch bool
+}
+
+// Segments returns the marked string broken into alternating literal and pattern segments in order. Empty literal
+// segments are omitted, so concatenating the texts of the segments yields the marked string.
+func (m Match) Segments() []Segment {
+	str, indexes, _ := parseTemplate(m)
+	var segments []Segment
+	last := 0
+	for _, index := range indexes {
+		if index[0] > last {
+			segments = append(segments, Segment{Text: str[last:index[0]]})
+		}
+		segments = append(segments, Segment{Text: str[index[0]:index[1]], IsMatch: true})
+		last = index[1]
+	}
+	if last < len(str) {
+		segments = append(segments, Segment{Text: str[last:]})
+	}
+	return segments
+}
+
+// Render returns the marked string with the patterns in place
+func (m Match) Render() string {
+	return fmt.Sprintf(m.Template, convertToInterfaceSlice(m.Patterns)...)
+}
+
+// Map returns a copy of the Match with each pattern replaced by the result of fn
+func (m Match) Map(fn func(string) string) Match {
+	patterns := make([]string, len(m.Patterns))
+	for i, pattern := range m.Patterns {
+		patterns[i] = fn(pattern)
+	}
+	m.Patterns = patterns
+	return m
+}
+
+// Wrap returns the marked string with each pattern surrounded by given prefix and suffix
+func (m Match) Wrap(prefix, suffix string) string {
+	return m.Map(func(pattern string) string { return prefix + pattern + suffix }).Render()
+}
+
+// Reflow returns the marked string with its paragraphs, separated by blank lines, re-wrapped to lines of at most width
+// runes. Whitespace inside patterns is kept, so each pattern stays on one line with the text attached to it, like the
+// punctuation following a word. Units longer than width are not broken and get a line of their own.
+func (m Match) Reflow(width int) string {
+	str, indexes, _ := parseTemplate(m)
+	var paragraphs [][]string
+	var units []string
+	next := 0
+	inPattern := func(i int) bool {
+		for next < len(indexes) && indexes[next][1] <= i {
+			next++
+		}
+		return next < len(indexes) && indexes[next][0] <= i
+	}
+	for i := 0; i < len(str); {
+		gapStart := i
+		for i < len(str) && !inPattern(i) {
+			r, size := utf8.DecodeRuneInString(str[i:])
+			if !unicode.IsSpace(r) {
+				break
+			}
+			i += size
+		}
+		if len(units) > 0 && strings.Count(str[gapStart:i], "\n") > 1 {
+			paragraphs = append(paragraphs, units)
+			units = nil
+		}
+
+		unitStart := i
+		for i < len(str) {
+			if inPattern(i) {
+				i = indexes[next][1]
+				continue
+			}
+			r, size := utf8.DecodeRuneInString(str[i:])
+			if unicode.IsSpace(r) {
+				break
+			}
+			i += size
+		}
+		if i > unitStart {
+			units = append(units, str[unitStart:i])
+		}
+	}
+	if len(units) > 0 {
+		paragraphs = append(paragraphs, units)
+	}
+
+	reflowed := make([]string, len(paragraphs))
+	for i, paragraph := range paragraphs {
+		var lines []string
+		line, lineLen := "", 0
+		for _, unit := range paragraph {
+			unitLen := utf8.RuneCountInString(unit)
+			if lineLen > 0 && lineLen+1+unitLen > width {
+				lines = append(lines, line)
+				line, lineLen = "", 0
+			}
+			if lineLen > 0 {
+				line += " "
+				lineLen++
+			}
+			line += unit
+			lineLen += unitLen
+		}
+		reflowed[i] = strings.Join(append(lines, line), "\n")
+	}
+	return strings.Join(reflowed, "\n\n")
+}
+
+// ReplaceMap returns the marked string with each pattern replaced by its value in subs, patterns not in subs are kept
+func (m Match) ReplaceMap(subs map[string]string) string {
+	return m.replaceMap(subs, func(pattern string) string { return pattern })
+}
+
+// ReplaceMapFold works like ReplaceMap but looks up the patterns in subs case-insensitively
+func (m Match) ReplaceMapFold(subs map[string]string) string {
+	lowerSubs := make(map[string]string, len(subs))
+	for key, value := range subs {
+		lowerSubs[strings.ToLower(key)] = value
+	}
+	return m.replaceMap(lowerSubs, strings.ToLower)
+}
+
+// ReplaceSmartCase works like ReplaceMapFold but adapts the case of each replacement to the case of the pattern it
+// replaces: lower case patterns get lower case replacements, upper case patterns get upper case replacements and
+// capitalized patterns get capitalized replacements. Replacements of other patterns are used as given.
+func (m Match) ReplaceSmartCase(subs map[string]string) string {
+	lowerSubs := make(map[string]string, len(subs))
+	for key, value := range subs {
+		lowerSubs[strings.ToLower(key)] = value
+	}
+	return m.Map(func(pattern string) string {
+		sub, ok := lowerSubs[strings.ToLower(pattern)]
+		if !ok {
+			return pattern
+		}
+		return matchCase(pattern, sub)
+	}).Render()
+}
+
+func (m Match) replaceMap(subs map[string]string, key func(string) string) string {
+	patterns := make([]string, len(m.Patterns))
+	for i, pattern := range m.Patterns {
+		patterns[i] = pattern
+		if sub, ok := subs[key(pattern)]; ok {
+			patterns[i] = sub
+		}
+	}
+	m.Patterns = patterns
+	return m.Render()
+}
+
+// Capture is a pattern with its start and end byte offsets in the marked string
+type Capture struct {
+	Pattern string
+	Start   int
+	End     int
+}
+
+// CategorizedMatch contains the patterns found by the MatcherFuncs of several categories with the category each of
+// them belongs to
+type CategorizedMatch struct {
+	Template string
+	Captures map[string][]Capture
+}
+
+// MatchCategories applies the MatcherFunc of each category in given rules on given string. When patterns overlap the
+// leftmost one wins, on equal start the pattern of the category whose name sorts first wins. The Template has a %s in
+// place of each kept pattern and Captures holds the kept patterns of each category in order.
+func MatchCategories(str string, rules map[string]MatcherFunc) CategorizedMatch {
+	categories := make([]string, 0, len(rules))
+	for category := range rules {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+
+	var candidates []capture
+	sourceCategories := make(map[*Match]string, len(categories))
+	for _, category := range categories {
+		match := rules[category](str)
+		_, matchCaptures := captures(&match)
+		candidates = append(candidates, matchCaptures...)
+		sourceCategories[&match] = category
+	}
+	resolved := resolveOverlaps(candidates)
+
+	categorized := CategorizedMatch{
+		Template: buildMatch(str, resolved).Template,
+		Captures: make(map[string][]Capture),
+	}
+	for _, c := range resolved {
+		category := sourceCategories[c.source]
+		pattern := Capture{Pattern: str[c.index[0]:c.index[1]], Start: c.index[0], End: c.index[1]}
+		categorized.Captures[category] = append(categorized.Captures[category], pattern)
+	}
+	return categorized
+}
+
+// Token is a typed piece of a tokenized string with its start and end byte offsets
+type Token struct {
+	Type  string
+	Text  string
+	Start int
+	End   int
+}
+
+// LabeledMatcher is a MatcherFunc whose patterns are tokens of the type given by Label
+type LabeledMatcher struct {
+	Label   string
+	Matcher MatcherFunc
+}
+
+// TextToken is the type of the tokens Tokenize creates for the parts of a string not matched by any rule
+const TextToken = "text"
+
+// Tokenize splits given string into tokens of the rules matching it. When patterns overlap the leftmost one wins, on
+// equal start the longest one wins and on equal length the one of the rule given first wins. The parts of the string
+// between the kept patterns become TextToken tokens, so the tokens cover the whole string. An error is returned if a
+// rule has no label or no MatcherFunc.
+func Tokenize(str string, rules []LabeledMatcher) ([]Token, error) {
+	var candidates []capture
+	sourceLabels := make(map[*Match]string, len(rules))
+	for i, rule := range rules {
+		if rule.Label == "" || rule.Matcher == nil {
+			return nil, fmt.Errorf("marker: rule %d must have a label and a MatcherFunc", i)
+		}
+		match := rule.Matcher(str)
+		_, matchCaptures := captures(&match)
+		candidates = append(candidates, matchCaptures...)
+		sourceLabels[&match] = rule.Label
+	}
+
+	var tokens []Token
+	last := 0
+	for _, c := range resolveLongestOverlaps(candidates) {
+		if last < c.index[0] {
+			tokens = append(tokens, Token{Type: TextToken, Text: str[last:c.index[0]], Start: last, End: c.index[0]})
+		}
+		if c.index[0] < c.index[1] {
+			token := Token{Type: sourceLabels[c.source], Text: str[c.index[0]:c.index[1]], Start: c.index[0], End: c.index[1]}
+			tokens = append(tokens, token)
+		}
+		last = c.index[1]
+	}
+	if last < len(str) {
+		tokens = append(tokens, Token{Type: TextToken, Text: str[last:], Start: last, End: len(str)})
+	}
+	return tokens, nil
+}
+
+type capture struct {
+	index  []int
+	source *Match
+	i      int
+}
+
+// parseTemplate returns the marked string with the start and end offsets of each placeholder of the template and the
+// index of the pattern it holds. Placeholders are either %s, holding the pattern following the one of the previous
+// placeholder, or indexed like %[2]s, holding the pattern with the given index counting from 1.
+func parseTemplate(m Match) (string, [][]int, []int) {
+	str := make([]byte, 0, len(m.Template))
+	var indexes [][]int
+	var patternIndexes []int
+	patternIndex := 0
+	for i := 0; i < len(m.Template); i++ {
+		if m.Template[i] != '%' || i+1 == len(m.Template) {
+			str = append(str, m.Template[i])
+			continue
+		}
+		placeholderIndex, placeholderLen := patternIndex, 2
+		if explicitIndex, explicitLen, ok := parseArgumentIndex(m.Template[i+1:]); ok {
+			placeholderIndex, placeholderLen = explicitIndex, explicitLen+2
+		}
+		switch {
+		case m.Template[i+1] == '%':
+			str = append(str, '%')
+			i++
+		case m.Template[i+placeholderLen-1] == 's' && placeholderIndex < len(m.Patterns):
+			pattern := m.Patterns[placeholderIndex]
+			indexes = append(indexes, []int{len(str), len(str) + len(pattern)})
+			patternIndexes = append(patternIndexes, placeholderIndex)
+			str = append(str, pattern...)
+			patternIndex = placeholderIndex + 1
+			i += placeholderLen - 1
+		default:
+			str = append(str, '%')
+		}
+	}
+	return string(str), indexes, patternIndexes
+}
+
+// parseArgumentIndex parses an explicit argument index like [2]s at the start of given string and returns it counting
+// from 0 with its length without the verb
+func parseArgumentIndex(str string) (int, int, bool) {
+	closing := strings.IndexByte(str, ']')
+	if len(str) == 0 || str[0] != '[' || closing < 0 || closing+1 == len(str) || str[closing+1] != 's' {
+		return 0, 0, false
+	}
+	index, err := strconv.Atoi(str[1:closing])
+	if err != nil || index < 1 {
+		return 0, 0, false
+	}
+	return index - 1, closing + 1, true
+}
+
+func captures(m *Match) (string, []capture) {
+	str, indexes, patternIndexes := parseTemplate(*m)
+	captures := make([]capture, len(indexes))
+	for i, index := range indexes {
+		captures[i] = capture{index: index, source: m, i: patternIndexes[i]}
+	}
+	return str, captures
+}
+
+func buildMatch(str string, captures []capture) Match {
+	indexes := make([][]int, len(captures))
+	for i, c := range captures {
+		indexes[i] = c.index
+	}
+	match := matchFromIndexes(str, indexes)
+	for i, c := range captures {
+		if c.source.Groups != nil {
+			if match.Groups == nil {
+				match.Groups = make([][]string, len(captures))
+			}
+			match.Groups[i] = c.source.Groups[c.i]
+		}
+		if c.source.Depths != nil {
+			if match.Depths == nil {
+				match.Depths = make([]int, len(captures))
+			}
+			match.Depths[i] = c.source.Depths[c.i]
+		}
+		if c.source.Labels != nil {
+			if match.Labels == nil {
+				match.Labels = make([]string, len(captures))
+			}
+			match.Labels[i] = c.source.Labels[c.i]
+		}
+		if c.source.Counts != nil {
+			if match.Counts == nil {
+				match.Counts = make([]int, len(captures))
+			}
+			match.Counts[i] = c.source.Counts[c.i]
+		}
+	}
+	return match
+}
+
+func filterMatch(m Match, keep func(i int, index []int) bool) Match {
+	str, all := captures(&m)
+	var kept []capture
+	for i, c := range all {
+		if keep(i, c.index) {
+			kept = append(kept, c)
+		}
+	}
+	return buildMatch(str, kept)
+}
+
+// resolveOverlaps is the overlap resolution shared by the combinators: captures are ordered by start offset and the ones
+// overlapping an already kept capture are dropped, so the leftmost capture wins and on equal start the capture coming
+// first in given slice, which is the one of the first listed MatcherFunc, wins.
+func resolveOverlaps(candidates []capture) []capture {
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].index[0] < candidates[j].index[0] })
+	var resolved []capture
+	for _, c := range candidates {
+		if len(resolved) > 0 && c.index[0] < resolved[len(resolved)-1].index[1] {
+			continue
+		}
+		resolved = append(resolved, c)
+	}
+	return resolved
+}
+
+// resolveLongestOverlaps works like resolveOverlaps but on equal start the longest capture wins
+func resolveLongestOverlaps(candidates []capture) []capture {
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i].index, candidates[j].index
+		return a[0] < b[0] || a[0] == b[0] && a[1] > b[1]
+	})
+	return resolveOverlaps(candidates)
+}
+
+func overlapsAny(c capture, others []capture) bool {
+	for _, other := range others {
+		if c.index[0] < other.index[1] && other.index[0] < c.index[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// matchMapped applies matcherFunc on given string with each rune replaced by mapping and maps the found patterns back
+// to the original string
+func matchMapped(str string, matcherFunc MatcherFunc, mapping func(rune) rune) Match {
+	mapped := make([]byte, 0, len(str))
+	offsets := make([]int, 0, len(str)+1)
+	for i, r := range str {
+		start := len(mapped)
+		mapped = append(mapped, string(mapping(r))...)
+		for j := start; j < len(mapped); j++ {
+			offsets = append(offsets, i)
+		}
+	}
+	offsets = append(offsets, len(str))
+
+	match := matcherFunc(string(mapped))
+	_, mappedCaptures := captures(&match)
+	for i := range mappedCaptures {
+		index := mappedCaptures[i].index
+		mappedCaptures[i].index = []int{offsets[index[0]], offsets[index[1]]}
+	}
+	return buildMatch(str, mappedCaptures)
+}
+
+// matchCase returns given replacement with the case of given pattern
+func matchCase(pattern, replacement string) string {
+	upper, lower := strings.ToUpper(pattern), strings.ToLower(pattern)
+	first, size := utf8.DecodeRuneInString(pattern)
+	switch {
+	case upper == lower:
+		return replacement
+	case pattern == lower:
+		return strings.ToLower(replacement)
+	case pattern == upper && utf8.RuneCountInString(pattern) > 1:
+		return strings.ToUpper(replacement)
+	case unicode.IsUpper(first) && pattern[size:] == lower[size:]:
+		replacementFirst, replacementSize := utf8.DecodeRuneInString(replacement)
+		return string(unicode.ToUpper(replacementFirst)) + strings.ToLower(replacement[replacementSize:])
+	}
+	return replacement
+}
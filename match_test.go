@@ -0,0 +1,229 @@
+package marker
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Indexes(t *testing.T) {
+	match := Match{Template: "%s costs 100%% and %s", Patterns: []string{"Skydome", "more"}}
+	assert.Equal(t, [][]int{{0, 7}, {23, 27}}, match.Indexes())
+
+	match = Match{Template: "no patterns"}
+	assert.Nil(t, match.Indexes())
+}
+
+func Test_ReplaceMap(t *testing.T) {
+	str := "ASAP send the PR, fyi btw"
+	match := MatchRegexp(regexp.MustCompile(`\w+`))(str)
+	subs := map[string]string{"asap": "as soon as possible", "PR": "pull request", "fyi": "for your information"}
+
+	assert.Equal(t, "ASAP send the pull request, for your information btw", match.ReplaceMap(subs))
+	assert.Equal(t, "as soon as possible send the pull request, for your information btw", match.ReplaceMapFold(subs))
+}
+
+func Test_Render(t *testing.T) {
+	str := "Skydome is 100% a data company"
+	assert.Equal(t, str, MatchAll("data")(str).Render())
+	assert.Equal(t, str, MatchRegexp(regexp.MustCompile(`\w+`))(str).Render())
+}
+
+func Test_Map(t *testing.T) {
+	match := MatchAll("data")("data is data")
+	mapped := match.Map(strings.ToUpper)
+
+	assert.Equal(t, "DATA is DATA", mapped.Render())
+	assert.Equal(t, []string{"data", "data"}, match.Patterns)
+}
+
+func Test_resolveOverlaps(t *testing.T) {
+	tests := []struct {
+		name       string
+		candidates [][]int
+		expected   [][]int
+	}{
+		{"No overlap", [][]int{{4, 6}, {0, 2}}, [][]int{{0, 2}, {4, 6}}},
+		{"Identical start, first listed shorter", [][]int{{0, 2}, {0, 5}}, [][]int{{0, 2}}},
+		{"Identical start, first listed longer", [][]int{{0, 5}, {0, 2}}, [][]int{{0, 5}}},
+		{"Identical spans", [][]int{{1, 3}, {1, 3}}, [][]int{{1, 3}}},
+		{"Nested, outer listed first", [][]int{{0, 10}, {2, 4}}, [][]int{{0, 10}}},
+		{"Nested, inner listed first", [][]int{{2, 4}, {0, 10}}, [][]int{{0, 10}}},
+		{"Nested, ending together", [][]int{{3, 10}, {0, 10}}, [][]int{{0, 10}}},
+		{"Partial overlap, left listed first", [][]int{{0, 5}, {3, 8}}, [][]int{{0, 5}}},
+		{"Partial overlap, right listed first", [][]int{{3, 8}, {0, 5}}, [][]int{{0, 5}}},
+		{"Adjacent", [][]int{{3, 6}, {0, 3}}, [][]int{{0, 3}, {3, 6}}},
+		{"Chain", [][]int{{0, 4}, {3, 7}, {6, 9}}, [][]int{{0, 4}, {6, 9}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			candidates := make([]capture, len(test.candidates))
+			for i, index := range test.candidates {
+				candidates[i] = capture{index: index, i: i}
+			}
+			var actual [][]int
+			for _, c := range resolveOverlaps(candidates) {
+				actual = append(actual, c.index)
+			}
+			assert.Equal(t, test.expected, actual)
+		})
+	}
+}
+
+func Test_Segments(t *testing.T) {
+	str := "data is data, big data"
+	segments := MatchAll("data")(str).Segments()
+
+	expectedSegments := []Segment{
+		{Text: "data", IsMatch: true},
+		{Text: " is "},
+		{Text: "data", IsMatch: true},
+		{Text: ", big "},
+		{Text: "data", IsMatch: true},
+	}
+	assert.Equal(t, expectedSegments, segments)
+
+	var reassembled strings.Builder
+	for _, segment := range segments {
+		reassembled.WriteString(segment.Text)
+	}
+	assert.Equal(t, str, reassembled.String())
+
+	assert.Equal(t, []Segment{{Text: "no data"}}, MatchAll("skydome")("no data").Segments())
+}
+
+func Test_MatchCategories(t *testing.T) {
+	str := "Mail help@example.com or see https://example.com/help before 2023-01-02, sure."
+	rules := map[string]MatcherFunc{
+		"email": MatchEmail(),
+		"url":   MatchRegexp(regexp.MustCompile(`https?://\S+`)),
+		"date":  MatchRegexp(regexp.MustCompile(`\d{4}-\d{2}-\d{2}`)),
+		"alias": MatchAll("help"),
+	}
+
+	actual := MatchCategories(str, rules)
+	expected := CategorizedMatch{
+		Template: "Mail %s@example.com or see %s before %s, sure.",
+		Captures: map[string][]Capture{
+			"alias": {{Pattern: "help", Start: 5, End: 9}},
+			"url":   {{Pattern: "https://example.com/help", Start: 29, End: 53}},
+			"date":  {{Pattern: "2023-01-02", Start: 61, End: 71}},
+		},
+	}
+	assert.Equal(t, expected, actual)
+
+	delete(rules, "alias")
+	actual = MatchCategories(str, rules)
+	assert.Equal(t, "Mail %s or see %s before %s, sure.", actual.Template)
+	assert.Equal(t, []Capture{{Pattern: "help@example.com", Start: 5, End: 21}}, actual.Captures["email"])
+	assert.Len(t, actual.Captures, 3)
+}
+
+func Test_Tokenize(t *testing.T) {
+	rules := []LabeledMatcher{
+		{Label: "day", Matcher: MatchDaysOfWeek()},
+		{Label: "word", Matcher: MatchRegexp(regexp.MustCompile(`\w+`))},
+		{Label: "url", Matcher: MatchRegexp(regexp.MustCompile(`https?://\S+`))},
+	}
+
+	actual, err := Tokenize("go to https://x.com on Monday", rules)
+	assert.NoError(t, err)
+	expected := []Token{
+		{Type: "word", Text: "go", Start: 0, End: 2},
+		{Type: TextToken, Text: " ", Start: 2, End: 3},
+		{Type: "word", Text: "to", Start: 3, End: 5},
+		{Type: TextToken, Text: " ", Start: 5, End: 6},
+		{Type: "url", Text: "https://x.com", Start: 6, End: 19},
+		{Type: TextToken, Text: " ", Start: 19, End: 20},
+		{Type: "word", Text: "on", Start: 20, End: 22},
+		{Type: TextToken, Text: " ", Start: 22, End: 23},
+		{Type: "day", Text: "Monday", Start: 23, End: 29},
+	}
+	assert.Equal(t, expected, actual)
+
+	actual, err = Tokenize("for sure", rules[:1])
+	assert.NoError(t, err)
+	assert.Equal(t, []Token{{Type: TextToken, Text: "for sure", Start: 0, End: 8}}, actual)
+
+	_, err = Tokenize("go", []LabeledMatcher{{Matcher: MatchAll("go")}})
+	assert.Error(t, err)
+}
+
+func Test_Wrap(t *testing.T) {
+	match := MatchDaysOfWeek()("Open Monday to Friday, closed on sunday")
+
+	assert.Equal(t, "Open **Monday** to **Friday**, closed on **sunday**", match.Wrap("**", "**"))
+	assert.Equal(t, "Open <b>Monday</b> to <b>Friday</b>, closed on <b>sunday</b>", match.Wrap("<b>", "</b>"))
+	assert.Equal(t, "no days", MatchDaysOfWeek()("no days").Wrap("[", "]"))
+}
+
+func Test_ReplaceSmartCase(t *testing.T) {
+	match := CaseInsensitive(MatchMultiple([]string{"color", "gray"}))("color, Color, COLOR and cOLoR gray: GRAY")
+	subs := map[string]string{"Color": "colour", "gray": "grey"}
+
+	expected := "colour, Colour, COLOUR and colour grey: GREY"
+	assert.Equal(t, expected, match.ReplaceSmartCase(subs))
+
+	assert.Equal(t, "Me and a", MatchMultiple([]string{"I", "a"})("I and a").ReplaceSmartCase(map[string]string{"i": "me"}))
+}
+
+func Test_IndexedPlaceholders(t *testing.T) {
+	str := "Lovelace, Ada: Turing, Alan"
+	matcher := MatchRegexp(regexp.MustCompile(`\w+, \w+`))
+
+	match := IndexedPlaceholders(matcher)(str)
+	assert.Equal(t, "%[1]s: %[2]s", match.Template)
+	assert.Equal(t, matcher(str).Patterns, match.Patterns)
+	assert.Equal(t, str, match.Render())
+	assert.Equal(t, matcher(str).Indexes(), match.Indexes())
+
+	match.Template = "%[2]s: %[1]s"
+	assert.Equal(t, "Turing, Alan: Lovelace, Ada", match.Render())
+	assert.Equal(t, [][]int{{0, 12}, {14, 27}}, match.Indexes())
+
+	match = Match{Template: "%[2]s and %s, %[1]s", Patterns: []string{"a", "b", "c"}, Labels: []string{"x", "y", "z"}}
+	assert.Equal(t, "b and c, a", match.Render())
+	assert.Equal(t, []string{"y", "z", "x"}, Limit(func(string) Match { return match }, 3)("").Labels)
+}
+
+func Test_Reflow(t *testing.T) {
+	str := "The quick brown fox jumps over the lazy dog, for   sure.\n\n  A supercalifragilistic word and New York\nstay."
+	match := MatchAny(MatchPhrase("New", "York"), MatchRegexp(WordRegexp))(str)
+
+	expected := "The quick brown fox\njumps over the lazy\ndog, for sure.\n\nA\nsupercalifragilistic\nword and New York\nstay."
+	assert.Equal(t, expected, match.Reflow(20))
+
+	assert.Equal(t, "one\ntwo", MatchAll("x")("one two").Reflow(1))
+	assert.Equal(t, "", MatchAll("x")(" \n ").Reflow(20))
+}
+
+func Test_NULSafety(t *testing.T) {
+	str := "\x00data\x00 is\x00 \x00Skydome\x00 data\x00"
+	matchers := []MatcherFunc{
+		MatchAll("\x00data"),
+		MatchN("data\x00", 1),
+		MatchMultiple([]string{"\x00Skydome\x00", "data"}),
+		MatchRegexp(regexp.MustCompile(`\x00\w+`)),
+		MatchSurrounded("\x00", "\x00"),
+		MatchCharRuns(1),
+		CaseInsensitive(MatchAll("skydome\x00")),
+		IndexedPlaceholders(MatchAll("\x00")),
+	}
+	for _, matcher := range matchers {
+		match := matcher(str)
+		assert.NotEmpty(t, match.Patterns)
+		assert.Equal(t, str, match.Render())
+
+		var segments strings.Builder
+		for _, segment := range match.Segments() {
+			segments.WriteString(segment.Text)
+		}
+		assert.Equal(t, str, segments.String())
+		for i, index := range match.Indexes() {
+			assert.Equal(t, match.Patterns[i], str[index[0]:index[1]])
+		}
+	}
+}
@@ -1,61 +1,169 @@
 package marker
 
 import (
+	"errors"
 	"fmt"
+	"html"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
 )
 
 // MatcherFunc returns a Match which contains information about found patterns
 type MatcherFunc func(string) Match
 
+// MatcherFuncE is a MatcherFunc which can fail
+type MatcherFuncE func(string) (Match, error)
+
+// Must creates a MatcherFunc from given MatcherFuncE which panics if the MatcherFuncE returns an error
+func Must(matcherFuncE MatcherFuncE) MatcherFunc {
+	return func(str string) Match {
+		match, err := matcherFuncE(str)
+		if err != nil {
+			panic(err)
+		}
+		return match
+	}
+}
+
+// Safe creates a MatcherFuncE from given MatcherFunc which returns an error instead of panicking
+func Safe(matcherFunc MatcherFunc) MatcherFuncE {
+	return func(str string) (match Match, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("marker: matcher panicked: %v", r)
+			}
+		}()
+		return matcherFunc(str), nil
+	}
+}
+
 // Match contains information about found patterns by MatcherFunc
 type Match struct {
 	Template string
 	Patterns []string
+	// Groups holds the sub-matches of each pattern for matchers that expose them, aligned with Patterns
+	Groups [][]string
+	// Depths holds the nesting depth of each pattern for matchers that expose it, aligned with Patterns
+	Depths []int
+	// Labels holds the label of each pattern for matchers that expose them, aligned with Patterns
+	Labels []string
+	// Counts holds the repeat count of each pattern for matchers that expose it, aligned with Patterns
+	Counts []int
+	// Offset is the byte offset of the marked string in a larger string, added to the offsets returned by Indexes. It
+	// is set by MatchAt and not carried over by the MatcherFuncs combining or filtering Matches, which return Matches
+	// of the marked string with Offset 0.
+	Offset int
 }
 
 // MatchAll creates a MatcherFunc that matches all patterns in given string
 func MatchAll(pattern string) MatcherFunc {
-	return func(str string) Match {
-		count := strings.Count(str, pattern)
-		return Match{
-			Template: strings.ReplaceAll(str, pattern, "%s"),
-			Patterns: fillSlice(make([]string, count), pattern),
-		}
-	}
+	return MatchN(pattern, -1)
 }
 
-// MatchN creates a MatcherFunc that matches first n patterns in given string
+// MatchN creates a MatcherFunc that matches first n patterns in given string, or all of them if n is negative
 func MatchN(pattern string, n int) MatcherFunc {
 	return func(str string) Match {
-		count := min(n, strings.Count(str, pattern))
-		return Match{
-			Template: strings.Replace(str, pattern, "%s", n),
-			Patterns: fillSlice(make([]string, count), pattern),
-		}
+		return matchFromIndexes(str, findStringIndexes(str, pattern, n))
 	}
 }
 
-// MatchMultiple creates a MatcherFunc that matches all string patterns from given slice in given string
+// MatchMultiple creates a MatcherFunc that matches all string patterns from given slice in given string. When patterns
+// overlap the leftmost one wins, on equal start the pattern given first wins.
 func MatchMultiple(patternsToMatch []string) MatcherFunc {
+	quoted := make([]string, len(patternsToMatch))
+	for i, pattern := range patternsToMatch {
+		quoted[i] = regexp.QuoteMeta(pattern)
+	}
 	return func(str string) Match {
-		patternMatchIndexes := findPatternMatchIndexes(str, patternsToMatch)
-		patterns := getPatternsInOrder(patternMatchIndexes)
-		return Match{
-			Template: replaceMultiple(str, patternsToMatch, "%s"),
-			Patterns: patterns,
+		if len(quoted) == 0 {
+			return matchFromIndexes(str, nil)
 		}
+		patternMatchIndexes := findPatternMatchIndexes(str, quoted)
+		starts := getKeys(patternMatchIndexes)
+		sort.Ints(starts)
+		indexes := make([][]int, len(starts))
+		for i, start := range starts {
+			indexes[i] = []int{start, start + len(patternMatchIndexes[start])}
+		}
+		return matchFromIndexes(str, indexes)
 	}
 }
 
 // MatchRegexp creates a MatcherFunc that matches given regexp in given string
 func MatchRegexp(r *regexp.Regexp) MatcherFunc {
 	return func(str string) Match {
-		return Match{
-			Template: r.ReplaceAllString(str, "%s"),
-			Patterns: r.FindAllString(str, -1),
+		return matchFromIndexes(str, r.FindAllStringIndex(str, -1))
+	}
+}
+
+// MatchRegexpMultiline creates a MatcherFunc that matches given regular expression compiled in multi-line mode, so ^ and
+// $ match at the start and end of each line. An error is returned if the expression does not compile.
+func MatchRegexpMultiline(pattern string) (MatcherFunc, error) {
+	return matchRegexpWithFlags(pattern, "m")
+}
+
+// MatchRegexpDotAll creates a MatcherFunc that matches given regular expression compiled with . matching line breaks
+// too. An error is returned if the expression does not compile.
+func MatchRegexpDotAll(pattern string) (MatcherFunc, error) {
+	return matchRegexpWithFlags(pattern, "s")
+}
+
+// MatchRegexps creates a MatcherFunc that matches each of given labeled regexps in given string and labels each pattern
+// with the label of its regexp. When patterns overlap the leftmost one wins, on equal start the longest one wins and on
+// equal length the one whose label sorts first wins.
+func MatchRegexps(patterns map[string]*regexp.Regexp) MatcherFunc {
+	labels := make([]string, 0, len(patterns))
+	for label := range patterns {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	return func(str string) Match {
+		var candidates []capture
+		sourceLabels := make(map[*Match]string, len(labels))
+		for _, label := range labels {
+			match := MatchRegexp(patterns[label])(str)
+			_, matchCaptures := captures(&match)
+			candidates = append(candidates, matchCaptures...)
+			sourceLabels[&match] = label
+		}
+		resolved := resolveLongestOverlaps(candidates)
+		match := buildMatch(str, resolved)
+		if len(resolved) > 0 {
+			match.Labels = make([]string, len(resolved))
+			for i, c := range resolved {
+				match.Labels[i] = sourceLabels[c.source]
+			}
+		}
+		return match
+	}
+}
+
+// ErrTimeout is returned by MatchRegexpTimeout when matching does not finish in time
+var ErrTimeout = errors.New("marker: matching timed out")
+
+// MatchRegexpTimeout creates a MatcherFuncE that works like MatchRegexp but gives up after given duration, returning a
+// Match without patterns and ErrTimeout. Matching cannot be interrupted, so it keeps running in the background until it
+// finishes and its result is discarded.
+func MatchRegexpTimeout(r *regexp.Regexp, d time.Duration) MatcherFuncE {
+	return func(str string) (Match, error) {
+		done := make(chan Match, 1)
+		go func() {
+			done <- MatchRegexp(r)(str)
+		}()
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+		select {
+		case match := <-done:
+			return match, nil
+		case <-timer.C:
+			return matchFromIndexes(str, nil), ErrTimeout
 		}
 	}
 }
@@ -68,14 +176,90 @@ func MatchTimestamp(layout string) MatcherFunc {
 	}
 }
 
-// MatchSurrounded creates a MatcherFunc that matches the patterns surrounded by given opening and closure strings
+// MatchSurrounded creates a MatcherFunc that matches the patterns surrounded by given opening and closure strings.
+// Each pattern ends at the first closure following an opening and starts at the last opening before that closure, so
+// the innermost of nested patterns is matched.
 func MatchSurrounded(opening string, closure string) MatcherFunc {
+	return Must(MatchSurroundedE(opening, closure))
+}
+
+// MatchSurroundedE creates a MatcherFuncE that matches the patterns surrounded by given opening and closure strings
+// and returns an error if the opening or closure string is empty
+func MatchSurroundedE(opening string, closure string) MatcherFuncE {
+	return func(str string) (Match, error) {
+		if opening == "" || closure == "" {
+			return Match{}, errors.New("marker: opening and closure must not be empty")
+		}
+		var indexes [][]int
+		for from := 0; ; {
+			start := strings.Index(str[from:], opening)
+			if start < 0 {
+				break
+			}
+			start += from
+			end := strings.Index(str[start+len(opening):], closure)
+			if end < 0 {
+				break
+			}
+			end += start + len(opening)
+			start += strings.LastIndex(str[start:end], opening)
+			indexes = append(indexes, []int{start, end + len(closure)})
+			from = end + len(closure)
+		}
+		return matchFromIndexes(str, indexes), nil
+	}
+}
+
+// MatchSurroundedGreedy creates a MatcherFunc that works like MatchSurrounded but matches the span from the first
+// opening to the last closure as one pattern. When opening and closure are the same, like quotes, the occurrences are
+// paired in order and an odd one out at the end is left unmatched. It panics if the opening or closure string is empty.
+func MatchSurroundedGreedy(opening string, closure string) MatcherFunc {
+	if opening != closure {
+		return Must(matchSurroundedGreedy(opening, closure))
+	}
+	delimiterRegexp := regexp.MustCompile(regexp.QuoteMeta(opening))
+	return Must(func(str string) (Match, error) {
+		if opening == "" {
+			return Match{}, errors.New("marker: opening and closure must not be empty")
+		}
+		occurrences := delimiterRegexp.FindAllStringIndex(str, -1)
+		if len(occurrences) < 2 {
+			return matchFromIndexes(str, nil), nil
+		}
+		last := occurrences[len(occurrences)-1-len(occurrences)%2]
+		return matchFromIndexes(str, [][]int{{occurrences[0][0], last[1]}}), nil
+	})
+}
+
+// MatchEmpty creates a MatcherFunc that matches empty pairs of given opening and closure strings like [], () or <!---->
+// in given string. When opening and closure are the same, like "", the occurrences are paired in order, so the middle
+// quotes of "a""b" are not an empty pair.
+func MatchEmpty(opening string, closure string) MatcherFunc {
+	empty := opening + closure
 	return func(str string) Match {
-		metaEscapedOpening := regexp.QuoteMeta(opening)
-		metaEscapedClosure := regexp.QuoteMeta(closure)
-		matchPattern := fmt.Sprintf("%s[^%s]*%s", metaEscapedOpening, metaEscapedOpening, metaEscapedClosure)
-		r, _ := regexp.Compile(matchPattern)
-		return MatchRegexp(r)(str)
+		if opening == "" || closure == "" {
+			return matchFromIndexes(str, nil)
+		}
+		if opening != closure {
+			return MatchAll(empty)(str)
+		}
+		var indexes [][]int
+		for i := 0; ; {
+			start := strings.Index(str[i:], opening)
+			if start < 0 {
+				break
+			}
+			start += i
+			end := strings.Index(str[start+len(opening):], closure)
+			if end < 0 {
+				break
+			}
+			if end == 0 {
+				indexes = append(indexes, []int{start, start + len(empty)})
+			}
+			i = start + len(opening) + end + len(closure)
+		}
+		return matchFromIndexes(str, indexes)
 	}
 }
 
@@ -96,6 +280,19 @@ func MatchEmail() MatcherFunc {
 	}
 }
 
+// MatchEmailDomains creates a MatcherFunc that matches the domains of emails in given string, leaving the local parts and
+// the @ signs in the template
+func MatchEmailDomains() MatcherFunc {
+	return func(str string) Match {
+		var indexes [][]int
+		for _, index := range EmailRegexp.FindAllStringIndex(str, -1) {
+			at := strings.LastIndexByte(str[index[0]:index[1]], '@')
+			indexes = append(indexes, []int{index[0] + at + 1, index[1]})
+		}
+		return matchFromIndexes(str, indexes)
+	}
+}
+
 var daysOfWeek = [14]string{"monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday",
 	"Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday", "Sunday"}
 
@@ -106,53 +303,2584 @@ func MatchDaysOfWeek() MatcherFunc {
 	}
 }
 
-func findPatternMatchIndexes(str string, patternsToMatch []string) map[int]string {
-	patternMatchIndexes := make(map[int]string)
-	pattern := strings.Join(patternsToMatch , "|")
-	patternRegex := regexp.MustCompile(pattern)
-	indices := patternRegex.FindAllStringIndex(str, -1)
-	for _, v := range(indices) {
-		start, end := v[0], v[1]
-		patternMatchIndexes[start] = str[start:end]
+// MatchNonASCII creates a MatcherFunc that matches runs of contiguous non-ASCII runes in given string
+func MatchNonASCII() MatcherFunc {
+	return func(str string) Match {
+		indexes := findRuneRunIndexes(str, func(r rune) bool { return r > unicode.MaxASCII })
+		return matchFromIndexes(str, indexes)
+	}
+}
+
+// MatchDiffHunkHeaders creates a MatcherFunc that matches unified diff hunk headers and file header pairs in given string.
+// Hunk headers expose old start, old count, new start and new count as groups, file headers expose the file path.
+func MatchDiffHunkHeaders() MatcherFunc {
+	return func(str string) Match {
+		var indexes [][]int
+		var groups [][]string
+		for _, submatchIndex := range DiffHeaderRegexp.FindAllStringSubmatchIndex(str, -1) {
+			if submatchIndex[2] >= 0 {
+				indexes = append(indexes, submatchIndex[:2])
+				groups = append(groups, submatchStrings(str, submatchIndex[2:10]))
+				continue
+			}
+			indexes = append(indexes, submatchIndex[10:12], submatchIndex[14:16])
+			groups = append(groups, submatchStrings(str, submatchIndex[12:14]), submatchStrings(str, submatchIndex[16:18]))
+		}
+		match := matchFromIndexes(str, indexes)
+		match.Groups = groups
+		return match
 	}
-	return patternMatchIndexes
 }
 
-func getPatternsInOrder(patternMatchIndexes map[int]string) []string {
-	matchIndexes := getKeys(patternMatchIndexes)
-	sort.Ints(matchIndexes)
-	patterns := make([]string, 0, len(patternMatchIndexes))
-	for _, index := range matchIndexes {
-		patterns = append(patterns, patternMatchIndexes[index])
+// MatchNumbersInRange creates a MatcherFunc that matches numbers within the inclusive range [lower, upper] in given string
+func MatchNumbersInRange(lower, upper float64) MatcherFunc {
+	return func(str string) Match {
+		var indexes [][]int
+		for _, index := range NumberRegexp.FindAllStringIndex(str, -1) {
+			number, err := strconv.ParseFloat(str[index[0]:index[1]], 64)
+			if err != nil || number < lower || number > upper {
+				continue
+			}
+			indexes = append(indexes, index)
+		}
+		return matchFromIndexes(str, indexes)
 	}
-	return patterns
 }
 
-func getKeys(m map[int]string) []int {
-	keys := make([]int, 0, len(m))
-	for key := range m {
-		keys = append(keys, key)
+// MatchPalindromes creates a MatcherFunc that matches words reading the same forwards and backwards, case-insensitively,
+// which are at least minLen letters long in given string
+func MatchPalindromes(minLen int) MatcherFunc {
+	return func(str string) Match {
+		var indexes [][]int
+		for _, index := range findRuneRunIndexes(str, unicode.IsLetter) {
+			word := []rune(strings.ToLower(str[index[0]:index[1]]))
+			if len(word) >= minLen && isPalindrome(word) {
+				indexes = append(indexes, index)
+			}
+		}
+		return matchFromIndexes(str, indexes)
 	}
-	return keys
 }
 
-func replaceMultiple(str string, patterns []string, with string) string {
-	for _, patternToMatch := range patterns {
-		str = strings.ReplaceAll(str, patternToMatch, with)
+// Placeholder styles supported by MatchTemplatePlaceholders
+const (
+	PrintfPlaceholders     = "printf"
+	GoTemplatePlaceholders = "go"
+	ShellPlaceholders      = "shell"
+)
+
+var placeholderStyleRegexps = map[string]*regexp.Regexp{
+	PrintfPlaceholders:     PrintfPlaceholderRegexp,
+	GoTemplatePlaceholders: GoTemplatePlaceholderRegexp,
+	ShellPlaceholders:      ShellPlaceholderRegexp,
+}
+
+// MatchTemplatePlaceholders creates a MatcherFunc that matches placeholder tokens of given style in given string.
+// Escaped percent signs (%%) are not treated as printf placeholders.
+func MatchTemplatePlaceholders(style string) MatcherFunc {
+	return func(str string) Match {
+		r, ok := placeholderStyleRegexps[style]
+		if !ok {
+			return matchFromIndexes(str, nil)
+		}
+		var indexes [][]int
+		for _, index := range r.FindAllStringIndex(str, -1) {
+			if str[index[0]:index[1]] != "%%" {
+				indexes = append(indexes, index)
+			}
+		}
+		return matchFromIndexes(str, indexes)
 	}
-	return str
 }
 
-func min(a, b int) int {
-	if a < b {
-		return a
+// MatchAroundIndex creates a MatcherFunc that keeps only the pattern of given MatcherFunc which contains or is nearest to
+// given byte index. A pattern contains the index if it starts at or before it and ends at or after it. On equal distance
+// the pattern starting before the index is preferred.
+func MatchAroundIndex(matcherFunc MatcherFunc, index int) MatcherFunc {
+	return func(str string) Match {
+		match := matcherFunc(str)
+		nearest, nearestDistance := -1, 0
+		_, patternIndexes, _ := parseTemplate(match)
+		for i, patternIndex := range patternIndexes {
+			distance := 0
+			if index < patternIndex[0] {
+				distance = patternIndex[0] - index
+			} else if index > patternIndex[1] {
+				distance = index - patternIndex[1]
+			}
+			if nearest < 0 || distance < nearestDistance {
+				nearest, nearestDistance = i, distance
+			}
+		}
+		return filterMatch(match, func(i int, _ []int) bool { return i == nearest })
+	}
+}
+
+var closingBrackets = map[rune]rune{'(': ')', '[': ']', '{': '}'}
+
+// MatchBracketsWithDepth creates a MatcherFunc that matches the brackets of every bracketed region in given string with
+// the nesting depth of the region, starting from 0. Since patterns cannot overlap, the opening and closing bracket of a
+// region are matched separately with the same depth. Brackets without a matching counterpart are left unmatched and do
+// not count towards the depth of the regions inside them.
+func MatchBracketsWithDepth() MatcherFunc {
+	return func(str string) Match {
+		var open []int
+		var indexes [][]int
+		isOpening := make(map[int]bool)
+		for i, r := range str {
+			if _, ok := closingBrackets[r]; ok {
+				open = append(open, i)
+				continue
+			}
+			if len(open) == 0 || closingBrackets[rune(str[open[len(open)-1]])] != r {
+				continue
+			}
+			start := open[len(open)-1]
+			open = open[:len(open)-1]
+			isOpening[start] = true
+			indexes = append(indexes, []int{start, start + 1}, []int{i, i + 1})
+		}
+		sort.Slice(indexes, func(i, j int) bool { return indexes[i][0] < indexes[j][0] })
+		match := matchFromIndexes(str, indexes)
+		depth := 0
+		for _, index := range indexes {
+			if !isOpening[index[0]] {
+				depth--
+			}
+			match.Depths = append(match.Depths, depth)
+			if isOpening[index[0]] {
+				depth++
+			}
+		}
+		return match
+	}
+}
+
+// MatchShortcodes creates a MatcherFunc that matches BBCode-style [tag]...[/tag] blocks in given string. Nested blocks
+// are matched as part of their outermost block and unclosed tags are left unmatched. Tag name and attributes of the
+// outermost tag are exposed as groups.
+func MatchShortcodes() MatcherFunc {
+	return func(str string) Match {
+		type tag struct {
+			index  []int
+			groups []string
+		}
+		var open, closed []tag
+		for _, submatchIndex := range ShortcodeTagRegexp.FindAllStringSubmatchIndex(str, -1) {
+			submatches := submatchStrings(str, submatchIndex[2:])
+			isClosing, name, attributes := submatches[0] != "", submatches[1], submatches[2]
+			if !isClosing {
+				open = append(open, tag{submatchIndex[:2], []string{name, strings.TrimLeft(attributes, "= ")}})
+				continue
+			}
+			for i := len(open) - 1; i >= 0 && attributes == ""; i-- {
+				if open[i].groups[0] == name {
+					closed = append(closed, tag{[]int{open[i].index[0], submatchIndex[1]}, open[i].groups})
+					open = open[:i]
+					break
+				}
+			}
+		}
+		sort.Slice(closed, func(i, j int) bool { return closed[i].index[0] < closed[j].index[0] })
+		var indexes [][]int
+		var groups [][]string
+		for _, block := range closed {
+			if len(indexes) > 0 && block.index[0] < indexes[len(indexes)-1][1] {
+				continue
+			}
+			indexes = append(indexes, block.index)
+			groups = append(groups, block.groups)
+		}
+		match := matchFromIndexes(str, indexes)
+		match.Groups = groups
+		return match
+	}
+}
+
+// MatchCamelCase creates a MatcherFunc that matches camelCase and PascalCase identifiers in given string and exposes
+// their component words as groups. Runs of capitals are kept together as acronyms and digits stay with the preceding word.
+func MatchCamelCase() MatcherFunc {
+	return func(str string) Match {
+		var indexes [][]int
+		var groups [][]string
+		for _, index := range IdentifierRegexp.FindAllStringIndex(str, -1) {
+			words := splitCamelCase(str[index[0]:index[1]])
+			if len(words) < 2 {
+				continue
+			}
+			indexes = append(indexes, index)
+			groups = append(groups, words)
+		}
+		match := matchFromIndexes(str, indexes)
+		match.Groups = groups
+		return match
+	}
+}
+
+// MatchGoDuration creates a MatcherFunc that matches Go duration strings like 1h30m, 500ms or 2.5s in given string
+func MatchGoDuration() MatcherFunc {
+	return func(str string) Match {
+		return MatchRegexp(GoDurationRegexp)(str)
+	}
+}
+
+// MatchDuration creates a MatcherFunc that matches Go duration strings and human readable durations like 3 hours or
+// 45 minutes in given string
+func MatchDuration() MatcherFunc {
+	return func(str string) Match {
+		return MatchRegexp(DurationRegexp)(str)
+	}
+}
+
+// MatchListMarkers creates a MatcherFunc that matches ordered (1. or 1)) and unordered (-, * or +) list markers at the
+// start of each line in given string, leaving the indentation before the markers unmatched
+func MatchListMarkers() MatcherFunc {
+	return func(str string) Match {
+		return matchListMarkers(str, false)
+	}
+}
+
+// MatchIndentedListMarkers creates a MatcherFunc that works like MatchListMarkers but includes the indentation before
+// the markers in the patterns
+func MatchIndentedListMarkers() MatcherFunc {
+	return func(str string) Match {
+		return matchListMarkers(str, true)
+	}
+}
+
+// MatchAny creates a MatcherFunc that matches the patterns of all given MatcherFuncs in given string. When patterns
+// overlap the leftmost one wins, on equal start the pattern of the MatcherFunc given first wins.
+func MatchAny(matcherFuncs ...MatcherFunc) MatcherFunc {
+	return func(str string) Match {
+		var candidates []capture
+		for _, matcherFunc := range matcherFuncs {
+			match := matcherFunc(str)
+			_, matchCaptures := captures(&match)
+			candidates = append(candidates, matchCaptures...)
+		}
+		return buildMatch(str, resolveOverlaps(candidates))
+	}
+}
+
+// MatchSequence creates a MatcherFunc that applies given MatcherFuncs in order, keeping only the patterns which do not
+// overlap the patterns kept from the preceding MatcherFuncs. Unlike MatchAny, an earlier MatcherFunc wins over a later
+// one even if its pattern starts further right.
+func MatchSequence(matcherFuncs ...MatcherFunc) MatcherFunc {
+	return func(str string) Match {
+		var kept []capture
+		for _, matcherFunc := range matcherFuncs {
+			match := matcherFunc(str)
+			_, matchCaptures := captures(&match)
+			for _, c := range matchCaptures {
+				if !overlapsAny(c, kept) {
+					kept = append(kept, c)
+				}
+			}
+		}
+		sort.SliceStable(kept, func(i, j int) bool { return kept[i].index[0] < kept[j].index[0] })
+		return buildMatch(str, kept)
+	}
+}
+
+// CollapseAdjacent creates a MatcherFunc that merges each run of identical patterns of given MatcherFunc separated only by
+// whitespace, like the words of "no no no", into its first pattern and removes the rest of the run from the template.
+// The number of patterns merged into each pattern is exposed as its count.
+func CollapseAdjacent(matcherFunc MatcherFunc) MatcherFunc {
+	return func(str string) Match {
+		match := matcherFunc(str)
+		original, all := captures(&match)
+		var collapsed strings.Builder
+		kept := make([]capture, 0, len(all))
+		counts := make([]int, 0, len(all))
+		last := 0
+		for i := 0; i < len(all); i++ {
+			c := all[i]
+			count := captureCount(c)
+			runEnd := c.index[1]
+			for i+1 < len(all) && isAdjacentRepeat(original, all[i], all[i+1]) {
+				i++
+				count += captureCount(all[i])
+				runEnd = all[i].index[1]
+			}
+			collapsed.WriteString(original[last:c.index[0]])
+			start := collapsed.Len()
+			collapsed.WriteString(original[c.index[0]:c.index[1]])
+			c.index = []int{start, collapsed.Len()}
+			kept = append(kept, c)
+			counts = append(counts, count)
+			last = runEnd
+		}
+		collapsed.WriteString(original[last:])
+
+		collapsedMatch := buildMatch(collapsed.String(), kept)
+		if len(counts) > 0 {
+			collapsedMatch.Counts = counts
+		}
+		return collapsedMatch
+	}
+}
+
+// MatchPriority creates a MatcherFunc that applies given MatcherFuncs in order of priority: the first MatcherFunc is
+// applied on the whole string and each following one only on the parts of the string between the patterns already
+// kept. Each pattern is labeled with the position of its MatcherFunc, "0" for the first one.
+func MatchPriority(matcherFuncs ...MatcherFunc) MatcherFunc {
+	return func(str string) Match {
+		type labeled struct {
+			capture
+			label string
+		}
+		var kept []labeled
+		for priority, matcherFunc := range matcherFuncs {
+			label := strconv.Itoa(priority)
+			var found []labeled
+			gapStart := 0
+			for i := 0; i <= len(kept); i++ {
+				gapEnd := len(str)
+				if i < len(kept) {
+					gapEnd = kept[i].index[0]
+				}
+				if gapStart < gapEnd {
+					match := matcherFunc(str[gapStart:gapEnd])
+					_, gapCaptures := captures(&match)
+					for _, c := range gapCaptures {
+						c.index = []int{gapStart + c.index[0], gapStart + c.index[1]}
+						found = append(found, labeled{c, label})
+					}
+				}
+				if i < len(kept) {
+					gapStart = kept[i].index[1]
+				}
+			}
+			kept = append(kept, found...)
+			sort.SliceStable(kept, func(i, j int) bool { return kept[i].index[0] < kept[j].index[0] })
+		}
+
+		resolved := make([]capture, len(kept))
+		labels := make([]string, len(kept))
+		for i, k := range kept {
+			resolved[i], labels[i] = k.capture, k.label
+		}
+		match := buildMatch(str, resolved)
+		if len(labels) > 0 {
+			match.Labels = labels
+		}
+		return match
+	}
+}
+
+// Limit creates a MatcherFunc that keeps only the first n patterns of given MatcherFunc
+func Limit(matcherFunc MatcherFunc, n int) MatcherFunc {
+	return func(str string) Match {
+		return filterMatch(matcherFunc(str), func(i int, _ []int) bool { return i < n })
+	}
+}
+
+// Cached creates a MatcherFunc that works like given MatcherFunc but remembers the Match of the last string it was
+// applied on and returns a copy of it when applied on the same string again instead of matching again. It is safe for
+// concurrent use.
+func Cached(matcherFunc MatcherFunc) MatcherFunc {
+	var mu sync.Mutex
+	var cached bool
+	var lastStr string
+	var lastMatch Match
+	return func(str string) Match {
+		mu.Lock()
+		if cached && str == lastStr {
+			defer mu.Unlock()
+			return copyMatch(lastMatch)
+		}
+		mu.Unlock()
+
+		match := matcherFunc(str)
+		mu.Lock()
+		cached, lastStr, lastMatch = true, str, copyMatch(match)
+		mu.Unlock()
+		return match
+	}
+}
+
+// CaseInsensitive creates a MatcherFunc that applies given MatcherFunc on the lower-cased string and returns the
+// patterns as they appear in the original string. Literal patterns of given MatcherFunc should be lower case.
+func CaseInsensitive(matcherFunc MatcherFunc) MatcherFunc {
+	return func(str string) Match {
+		return matchMapped(str, matcherFunc, unicode.ToLower)
+	}
+}
+
+// NormalizeDiacritics creates a MatcherFunc that applies given MatcherFunc on given string with the Latin letters with
+// diacritics folded to their base letters, like é to e, and returns the patterns as they appear in the original string.
+// Literal patterns of given MatcherFunc should be written without diacritics.
+func NormalizeDiacritics(matcherFunc MatcherFunc) MatcherFunc {
+	return func(str string) Match {
+		return matchMapped(str, matcherFunc, foldDiacritic)
+	}
+}
+
+// IndexedPlaceholders creates a MatcherFunc that works like given MatcherFunc but writes the placeholders of the template
+// with explicit argument indexes like %[1]s and %[2]s, so the patterns can be reordered by editing the template.
+// Render, Indexes and the other methods of Match understand both kinds of placeholders.
+func IndexedPlaceholders(matcherFunc MatcherFunc) MatcherFunc {
+	return func(str string) Match {
+		match := matcherFunc(str)
+		original, indexes, patternIndexes := parseTemplate(match)
+		var template strings.Builder
+		last := 0
+		for i, index := range indexes {
+			template.WriteString(escapeTemplate(original[last:index[0]]))
+			fmt.Fprintf(&template, "%%[%d]s", patternIndexes[i]+1)
+			last = index[1]
+		}
+		template.WriteString(escapeTemplate(original[last:]))
+		match.Template = template.String()
+		return match
+	}
+}
+
+// MatchBytes creates a MatcherFunc that matches runs of contiguous bytes satisfying given predicate in given string.
+// It operates on raw bytes, so multi-byte runes may be split.
+func MatchBytes(predicate func(byte) bool) MatcherFunc {
+	return func(str string) Match {
+		var indexes [][]int
+		start := -1
+		for i := 0; i <= len(str); i++ {
+			if i < len(str) && predicate(str[i]) {
+				if start < 0 {
+					start = i
+				}
+			} else if start >= 0 {
+				indexes = append(indexes, []int{start, i})
+				start = -1
+			}
+		}
+		return matchFromIndexes(str, indexes)
+	}
+}
+
+var hexRunRegexp = regexp.MustCompile(`\b[0-9a-f]+\b`)
+
+// MatchGitSHA creates a MatcherFunc that matches abbreviated and full SHA-1 commit hashes of 7 to 40 lowercase hex
+// characters in given string
+func MatchGitSHA() MatcherFunc {
+	return MatchGitSHALength(7, 40)
+}
+
+// MatchGitSHALength creates a MatcherFunc that matches commit hashes of minLen to maxLen lowercase hex characters in
+// given string. Longer hex runs are not matched partially and runs without any digit are skipped to avoid words like
+// "defaced". Nothing is matched if minLen is greater than maxLen.
+func MatchGitSHALength(minLen, maxLen int) MatcherFunc {
+	return func(str string) Match {
+		var indexes [][]int
+		for _, index := range hexRunRegexp.FindAllStringIndex(str, -1) {
+			length := index[1] - index[0]
+			if length >= minLen && length <= maxLen && strings.ContainsAny(str[index[0]:index[1]], "0123456789") {
+				indexes = append(indexes, index)
+			}
+		}
+		return matchFromIndexes(str, indexes)
+	}
+}
+
+// MatchGitRef creates a MatcherFunc that matches fully qualified git references like refs/heads/main in given string
+func MatchGitRef() MatcherFunc {
+	return func(str string) Match {
+		return MatchRegexp(GitRefRegexp)(str)
+	}
+}
+
+// MatchInRange creates a MatcherFunc that keeps only the patterns of given MatcherFunc lying entirely within the byte
+// range [start, end) of given string. Patterns straddling a boundary of the range are left unmatched.
+func MatchInRange(matcherFunc MatcherFunc, start, end int) MatcherFunc {
+	return func(str string) Match {
+		return filterMatch(matcherFunc(str), func(_ int, index []int) bool {
+			return index[0] >= start && index[1] <= end
+		})
+	}
+}
+
+// MatchCSVFields creates a MatcherFunc that matches the fields of CSV records in given string according to RFC 4180.
+// Surrounding quotes of quoted fields are left in the template and the unescaped value of each field is exposed as a group.
+func MatchCSVFields() MatcherFunc {
+	return func(str string) Match {
+		return matchCSVFields(str, false)
+	}
+}
+
+// MatchQuotedCSVFields creates a MatcherFunc that works like MatchCSVFields but includes the surrounding quotes of
+// quoted fields in the patterns
+func MatchQuotedCSVFields() MatcherFunc {
+	return func(str string) Match {
+		return matchCSVFields(str, true)
+	}
+}
+
+// MatchPrefix creates a MatcherFunc that matches given pattern only at the start of given string
+func MatchPrefix(pattern string) MatcherFunc {
+	return func(str string) Match {
+		if pattern == "" || !strings.HasPrefix(str, pattern) {
+			return matchFromIndexes(str, nil)
+		}
+		return matchFromIndexes(str, [][]int{{0, len(pattern)}})
+	}
+}
+
+// MatchSuffix creates a MatcherFunc that matches given pattern only at the end of given string
+func MatchSuffix(pattern string) MatcherFunc {
+	return func(str string) Match {
+		if pattern == "" || !strings.HasSuffix(str, pattern) {
+			return matchFromIndexes(str, nil)
+		}
+		return matchFromIndexes(str, [][]int{{len(str) - len(pattern), len(str)}})
+	}
+}
+
+// Labels of the patterns matched by MatchInterleave
+const (
+	OddLabel  = "odd"
+	EvenLabel = "even"
+)
+
+// MatchInterleave creates a MatcherFunc that alternates between given MatcherFuncs in match order: the 1st, 3rd, 5th...
+// patterns are taken from a and labeled OddLabel, the 2nd, 4th, 6th... patterns are taken from b and labeled EvenLabel.
+// Each pattern is the first one of its MatcherFunc starting after the previous pattern, and the alternation stops when
+// the MatcherFunc in turn has no such pattern.
+func MatchInterleave(a, b MatcherFunc) MatcherFunc {
+	return func(str string) Match {
+		matchA, matchB := a(str), b(str)
+		_, capturesA := captures(&matchA)
+		_, capturesB := captures(&matchB)
+		turns := [2][]capture{capturesA, capturesB}
+		labels := [2]string{OddLabel, EvenLabel}
+
+		var interleaved []capture
+		var interleavedLabels []string
+		position := 0
+		for turn := 0; ; turn = 1 - turn {
+			next := -1
+			for i, c := range turns[turn] {
+				if c.index[0] >= position {
+					next = i
+					break
+				}
+			}
+			if next < 0 {
+				break
+			}
+			c := turns[turn][next]
+			interleaved = append(interleaved, c)
+			interleavedLabels = append(interleavedLabels, labels[turn])
+			position = max(c.index[1], c.index[0]+1)
+		}
+
+		match := buildMatch(str, interleaved)
+		match.Labels = interleavedLabels
+		return match
+	}
+}
+
+// MatchAnagramsOf creates a MatcherFunc that matches words which are case-insensitive anagrams of given word in given
+// string, including the word itself
+func MatchAnagramsOf(word string) MatcherFunc {
+	return func(str string) Match {
+		return matchAnagrams(str, word, true)
+	}
+}
+
+// MatchStrictAnagramsOf creates a MatcherFunc that works like MatchAnagramsOf but does not match the word itself
+func MatchStrictAnagramsOf(word string) MatcherFunc {
+	return func(str string) Match {
+		return matchAnagrams(str, word, false)
+	}
+}
+
+// MatchFractions creates a MatcherFunc that matches Unicode vulgar fractions like ½ and slash fractions like 3/4 with a
+// denominator up to 16 in given string. Slash separated parts of longer sequences like dates are not matched.
+func MatchFractions() MatcherFunc {
+	return MatchFractionsWithMaxDenominator(16)
+}
+
+// MatchFractionsWithMaxDenominator creates a MatcherFunc that works like MatchFractions but matches slash fractions
+// with a denominator up to given maximum
+func MatchFractionsWithMaxDenominator(maxDenominator int) MatcherFunc {
+	return func(str string) Match {
+		var indexes [][]int
+		for _, submatchIndex := range FractionRegexp.FindAllStringSubmatchIndex(str, -1) {
+			start, end := submatchIndex[0], submatchIndex[1]
+			if submatchIndex[2] >= 0 {
+				denominator, err := strconv.Atoi(str[submatchIndex[4]:submatchIndex[5]])
+				isPartOfSequence := (start > 0 && str[start-1] == '/') || (end < len(str) && str[end] == '/')
+				if err != nil || denominator == 0 || denominator > maxDenominator || isPartOfSequence {
+					continue
+				}
+			}
+			indexes = append(indexes, []int{start, end})
+		}
+		return matchFromIndexes(str, indexes)
+	}
+}
+
+// MaxPatternLength creates a MatcherFunc that leaves the patterns of given MatcherFunc longer than n bytes unmatched
+func MaxPatternLength(matcherFunc MatcherFunc, n int) MatcherFunc {
+	return func(str string) Match {
+		return filterMatch(matcherFunc(str), func(_ int, index []int) bool { return index[1]-index[0] <= n })
+	}
+}
+
+// Whitelist creates a MatcherFunc that keeps only the patterns of given MatcherFunc which are in given set of allowed
+// patterns. The other patterns are left in the template as literal text.
+func Whitelist(matcherFunc MatcherFunc, allowed map[string]struct{}) MatcherFunc {
+	return func(str string) Match {
+		return whitelist(matcherFunc(str), allowed, func(pattern string) string { return pattern })
+	}
+}
+
+// WhitelistFold creates a MatcherFunc that works like Whitelist but compares the patterns with the allowed patterns
+// under Unicode case-folding
+func WhitelistFold(matcherFunc MatcherFunc, allowed map[string]struct{}) MatcherFunc {
+	folded := make(map[string]struct{}, len(allowed))
+	for pattern := range allowed {
+		folded[strings.ToLower(pattern)] = struct{}{}
+	}
+	return func(str string) Match {
+		return whitelist(matcherFunc(str), folded, strings.ToLower)
+	}
+}
+
+// MatchPrecededBy creates a MatcherFunc that keeps only the patterns of given MatcherFunc which are immediately preceded
+// by given prefix in given string. The prefix is left in the template.
+func MatchPrecededBy(prefix string, matcherFunc MatcherFunc) MatcherFunc {
+	return func(str string) Match {
+		return filterMatch(matcherFunc(str), func(_ int, index []int) bool {
+			return strings.HasSuffix(str[:index[0]], prefix)
+		})
 	}
-	return b
 }
 
-func fillSlice(s []string, v string) []string {
-	for i := range s {
-		s[i] = v
+// MatchFollowedBy creates a MatcherFunc that keeps only the patterns of given MatcherFunc which are immediately followed
+// by given suffix in given string. The suffix is left in the template.
+func MatchFollowedBy(matcherFunc MatcherFunc, suffix string) MatcherFunc {
+	return func(str string) Match {
+		return filterMatch(matcherFunc(str), func(_ int, index []int) bool {
+			return strings.HasPrefix(str[index[1]:], suffix)
+		})
+	}
+}
+
+// MinGap creates a MatcherFunc that keeps a pattern of given MatcherFunc only if it starts at least gap bytes after the
+// start of the previously kept pattern. The first pattern is always kept.
+func MinGap(matcherFunc MatcherFunc, gap int) MatcherFunc {
+	return func(str string) Match {
+		last := -1
+		return filterMatch(matcherFunc(str), func(_ int, index []int) bool {
+			if last >= 0 && index[0]-last < gap {
+				return false
+			}
+			last = index[0]
+			return true
+		})
+	}
+}
+
+var legalSymbols = []rune{'™', '®', '©', '℠', '℗', '§', '¶'}
+
+// MatchSymbols creates a MatcherFunc that matches each occurrence of the runes in given set in given string
+func MatchSymbols(set []rune) MatcherFunc {
+	return func(str string) Match {
+		var indexes [][]int
+		for i, r := range str {
+			if containsRune(set, r) {
+				indexes = append(indexes, []int{i, i + utf8.RuneLen(r)})
+			}
+		}
+		return matchFromIndexes(str, indexes)
+	}
+}
+
+// MatchLegalSymbols creates a MatcherFunc that matches trademark, copyright, section and paragraph symbols in given string
+func MatchLegalSymbols() MatcherFunc {
+	return MatchSymbols(legalSymbols)
+}
+
+// InvisibleRunes are the invisible and blank-looking runes matched by MatchInvisibles: the byte order mark, zero-width
+// spaces, joiners and marks, directional controls, soft hyphen and the non-breaking and other special width spaces
+var InvisibleRunes = []rune{
+	'\uFEFF', '\u200B', '\u200C', '\u200D', '\u2060', '\u180E', '\u00AD', '\u034F', '\u061C',
+	'\u200E', '\u200F', '\u202A', '\u202B', '\u202C', '\u202D', '\u202E', '\u2066', '\u2067', '\u2068', '\u2069',
+	'\u00A0', '\u2007', '\u202F', '\u2000', '\u2001', '\u2002', '\u2003', '\u2004', '\u2005', '\u2006', '\u2008',
+	'\u2009', '\u200A', '\u205F', '\u3000', '\u3164', '\uFFA0', '\u2800',
+}
+
+// MatchInvisibles creates a MatcherFunc that matches each occurrence of InvisibleRunes in given string. Use MatchSymbols
+// to match a different set.
+func MatchInvisibles() MatcherFunc {
+	return MatchSymbols(InvisibleRunes)
+}
+
+// MatchRune creates a MatcherFunc that matches each occurrence of given rune in given string
+func MatchRune(r rune) MatcherFunc {
+	return MatchSymbols([]rune{r})
+}
+
+// MatchRuneN creates a MatcherFunc that matches the first n occurrences of given rune in given string
+func MatchRuneN(r rune, n int) MatcherFunc {
+	return Limit(MatchRune(r), n)
+}
+
+// MatchRedactions creates a MatcherFunc that matches already redacted spans in given string: [REDACTED] markers and
+// runs of at least four of the given mask runes, which default to '*' and 'X'
+func MatchRedactions(maskRunes ...rune) MatcherFunc {
+	if len(maskRunes) == 0 {
+		maskRunes = []rune{'*', 'X'}
+	}
+	alternatives := []string{regexp.QuoteMeta("[REDACTED]")}
+	for _, r := range maskRunes {
+		alternatives = append(alternatives, fmt.Sprintf("(?:%s){4,}", regexp.QuoteMeta(string(r))))
+	}
+	r := regexp.MustCompile(strings.Join(alternatives, "|"))
+	return func(str string) Match {
+		return MatchRegexp(r)(str)
+	}
+}
+
+// MatchGlob creates a MatcherFunc that matches whole tokens of given string matching given shell-style glob pattern.
+// * matches any run and ? matches any single character except whitespace, brackets, quotes, commas and semicolons,
+// [...] matches a character class and [!...] a negated one. Matches adjacent to letters, digits or underscores are skipped.
+// Malformed classes like [z-a] or an unclosed [ are matched literally.
+func MatchGlob(pattern string) MatcherFunc {
+	r := regexp.MustCompile(globToRegexp(pattern))
+	return func(str string) Match {
+		var indexes [][]int
+		for _, index := range r.FindAllStringIndex(str, -1) {
+			before, _ := utf8.DecodeLastRuneInString(str[:index[0]])
+			after, _ := utf8.DecodeRuneInString(str[index[1]:])
+			if !isWordRune(before) && !isWordRune(after) {
+				indexes = append(indexes, index)
+			}
+		}
+		return matchFromIndexes(str, indexes)
+	}
+}
+
+// MatchGlobSubstring creates a MatcherFunc that works like MatchGlob but also matches inside longer tokens
+func MatchGlobSubstring(pattern string) MatcherFunc {
+	r := regexp.MustCompile(globToRegexp(pattern))
+	return func(str string) Match {
+		return MatchRegexp(r)(str)
+	}
+}
+
+var numberWordValues = map[string]int{
+	"zero": 0, "one": 1, "two": 2, "three": 3, "four": 4, "five": 5, "six": 6, "seven": 7, "eight": 8, "nine": 9,
+	"ten": 10, "eleven": 11, "twelve": 12, "thirteen": 13, "fourteen": 14, "fifteen": 15, "sixteen": 16,
+	"seventeen": 17, "eighteen": 18, "nineteen": 19, "twenty": 20, "thirty": 30, "forty": 40, "fifty": 50,
+	"sixty": 60, "seventy": 70, "eighty": 80, "ninety": 90,
+}
+
+var numberWordScales = map[string]int{"hundred": 100, "thousand": 1000, "million": 1000000, "billion": 1000000000}
+
+// MatchNumberWords creates a MatcherFunc that matches spelled-out numbers like twenty-one, three hundred or one hundred
+// and five in given string, case-insensitively. The value of each number is exposed as a group.
+func MatchNumberWords() MatcherFunc {
+	return func(str string) Match {
+		words := findRuneRunIndexes(str, unicode.IsLetter)
+		var indexes [][]int
+		var groups [][]string
+		for i := 0; i < len(words); {
+			next, value := parseNumberWords(str, words, i)
+			if next == i {
+				i++
+				continue
+			}
+			indexes = append(indexes, []int{words[i][0], words[next-1][1]})
+			groups = append(groups, []string{strconv.Itoa(value)})
+			i = next
+		}
+		match := matchFromIndexes(str, indexes)
+		match.Groups = groups
+		return match
+	}
+}
+
+// MatchShouting creates a MatcherFunc that matches runs of at least minWords consecutive all-caps words in given string.
+// Single-letter words are not counted but do not break a run, sentence punctuation and other words do.
+func MatchShouting(minWords int) MatcherFunc {
+	return MatchShoutingWithMinWordLength(minWords, 2)
+}
+
+// MatchShoutingWithMinWordLength creates a MatcherFunc that works like MatchShouting but does not count all-caps words
+// shorter than minWordLen letters, like acronyms
+func MatchShoutingWithMinWordLength(minWords, minWordLen int) MatcherFunc {
+	return func(str string) Match {
+		var indexes [][]int
+		runStart, runEnd, runCount, previousEnd := -1, 0, 0, 0
+		closeRun := func() {
+			if runStart >= 0 && runCount >= minWords {
+				indexes = append(indexes, []int{runStart, runEnd})
+			}
+			runStart, runCount = -1, 0
+		}
+		for _, index := range findRuneRunIndexes(str, unicode.IsLetter) {
+			word := str[index[0]:index[1]]
+			if strings.ToUpper(word) != word || strings.ToLower(word) == word {
+				closeRun()
+				continue
+			}
+			if runStart >= 0 && strings.ContainsAny(str[previousEnd:index[0]], ".!?") {
+				closeRun()
+			}
+			previousEnd = index[1]
+			if utf8.RuneCountInString(word) < minWordLen {
+				continue
+			}
+			if runStart < 0 {
+				runStart = index[0]
+			}
+			runEnd = index[1]
+			runCount++
+		}
+		closeRun()
+		return matchFromIndexes(str, indexes)
+	}
+}
+
+var fileExtensions = []string{"avi", "bak", "bat", "cfg", "conf", "cpp", "csv", "doc", "docx", "exe", "gif", "go",
+	"gz", "htm", "html", "ini", "java", "jpeg", "jpg", "js", "json", "log", "md", "mov", "mp3", "mp4", "pdf", "png",
+	"ppt", "pptx", "py", "rb", "sh", "svg", "tar", "tmp", "ts", "txt", "wav", "xls", "xlsx", "xml", "yaml", "yml", "zip"}
+
+// MatchDomains creates a MatcherFunc that matches bare domain names like example.com in given string. Domains whose
+// last label is a common file extension, like report.pdf, and domains of emails and URLs are not matched.
+func MatchDomains() MatcherFunc {
+	return func(str string) Match {
+		return matchDomains(str, func(tld string) bool { return !containsString(fileExtensions, tld) })
+	}
+}
+
+// MatchDomainsWithTLDs creates a MatcherFunc that works like MatchDomains but matches only the domains whose last label
+// is in given list of top-level domains
+func MatchDomainsWithTLDs(tlds []string) MatcherFunc {
+	return func(str string) Match {
+		return matchDomains(str, func(tld string) bool { return containsString(tlds, tld) })
+	}
+}
+
+// MatchHTMLEntities creates a MatcherFunc that matches named (&amp;), decimal (&#169;) and hexadecimal (&#x1F600;)
+// HTML character references in given string. Unknown named references are not matched.
+func MatchHTMLEntities() MatcherFunc {
+	return func(str string) Match {
+		var indexes [][]int
+		for _, index := range HTMLEntityRegexp.FindAllStringIndex(str, -1) {
+			entity := str[index[0]:index[1]]
+			if html.UnescapeString(entity) != entity {
+				indexes = append(indexes, index)
+			}
+		}
+		return matchFromIndexes(str, indexes)
+	}
+}
+
+// MatchBalancedTags creates a MatcherFunc that matches whole elements with given tag name in given string, including
+// nested elements with the same name. Tag names are case-insensitive, self-closing tags are matched on their own and
+// unclosed tags are left unmatched.
+func MatchBalancedTags(tagName string) MatcherFunc {
+	r := regexp.MustCompile(fmt.Sprintf(`(?i)<(/?)%s(?:\s[^<>]*?)?(/?)>`, regexp.QuoteMeta(tagName)))
+	return func(str string) Match {
+		var open []int
+		var elements [][]int
+		for _, submatchIndex := range r.FindAllStringSubmatchIndex(str, -1) {
+			isClosing := submatchIndex[3] > submatchIndex[2]
+			isSelfClosing := submatchIndex[5] > submatchIndex[4]
+			switch {
+			case isSelfClosing:
+				elements = append(elements, submatchIndex[:2])
+			case !isClosing:
+				open = append(open, submatchIndex[0])
+			case len(open) > 0:
+				elements = append(elements, []int{open[len(open)-1], submatchIndex[1]})
+				open = open[:len(open)-1]
+			}
+		}
+		return matchFromIndexes(str, outermostIndexes(elements))
+	}
+}
+
+// MatchUnknownWords creates a MatcherFunc that matches words of given string which are not in given dictionary of lower
+// case words. Contractions like don't and hyphenated words like well-known are looked up as single words.
+func MatchUnknownWords(dict map[string]struct{}) MatcherFunc {
+	return func(str string) Match {
+		return matchUnknownWords(str, dict, WordRegexp.FindAllStringIndex(str, -1))
+	}
+}
+
+// MatchUnknownWordParts creates a MatcherFunc that works like MatchUnknownWords but looks up the parts of contractions
+// and hyphenated words separately
+func MatchUnknownWordParts(dict map[string]struct{}) MatcherFunc {
+	return func(str string) Match {
+		return matchUnknownWords(str, dict, findRuneRunIndexes(str, unicode.IsLetter))
+	}
+}
+
+// MatchAgainst creates a MatcherFunc that matches the substrings of given string of at least minLen runes which also
+// appear in given reference string. Longer substrings are preferred: the longest substring starting at each position is
+// taken in order of decreasing length, cut short where it would overlap an already taken one. The reference is indexed
+// once with a suffix automaton in O(len(reference)) time and space, finding the candidates is O(len(str)) and choosing
+// between them is dominated by sorting them.
+func MatchAgainst(reference string, minLen int) MatcherFunc {
+	automaton := newSuffixAutomaton(reverseRunes([]rune(reference)))
+	if minLen < 1 {
+		minLen = 1
+	}
+	return func(str string) Match {
+		runes := []rune(str)
+		offsets := make([]int, 0, len(runes)+1)
+		for i := range str {
+			offsets = append(offsets, i)
+		}
+		offsets = append(offsets, len(str))
+
+		reversedLengths := automaton.longestSuffixes(reverseRunes(runes))
+		lengths := reverseInts(reversedLengths)
+		starts := make([]int, 0, len(runes))
+		for i, length := range lengths {
+			if length >= minLen {
+				starts = append(starts, i)
+			}
+		}
+		sort.SliceStable(starts, func(i, j int) bool { return lengths[starts[i]] > lengths[starts[j]] })
+
+		taken := make([]bool, len(runes))
+		var indexes [][]int
+		for _, start := range starts {
+			end := start
+			for end < start+lengths[start] && !taken[end] {
+				end++
+			}
+			if end-start < minLen {
+				continue
+			}
+			for i := start; i < end; i++ {
+				taken[i] = true
+			}
+			indexes = append(indexes, []int{offsets[start], offsets[end]})
+		}
+		sort.Slice(indexes, func(i, j int) bool { return indexes[i][0] < indexes[j][0] })
+		return matchFromIndexes(str, indexes)
+	}
+}
+
+var timeZoneAbbreviations = []string{"UTC", "GMT", "EST", "EDT", "CST", "CDT", "MST", "MDT", "PST", "PDT", "AKST",
+	"AKDT", "HST", "WET", "WEST", "CET", "CEST", "EET", "EEST", "MSK", "IST", "JST", "KST", "AEST", "AEDT", "ACST",
+	"AWST", "NZST", "NZDT"}
+
+// MatchTimeZones creates a MatcherFunc that matches common time zone abbreviations like UTC or PST and numeric offsets
+// like +05:30, -0800 or Z following a time in given string
+func MatchTimeZones() MatcherFunc {
+	return MatchTimeZonesWith(timeZoneAbbreviations)
+}
+
+// MatchTimeZonesWith creates a MatcherFunc that works like MatchTimeZones but matches given time zone abbreviations
+func MatchTimeZonesWith(abbreviations []string) MatcherFunc {
+	quoted := make([]string, len(abbreviations))
+	for i, abbreviation := range abbreviations {
+		quoted[i] = regexp.QuoteMeta(abbreviation)
+	}
+	abbreviationRegexp := regexp.MustCompile(fmt.Sprintf(`\b(?:%s)\b`, strings.Join(quoted, "|")))
+	return func(str string) Match {
+		var indexes [][]int
+		if len(abbreviations) > 0 {
+			indexes = abbreviationRegexp.FindAllStringIndex(str, -1)
+		}
+		for _, submatchIndex := range TimeZoneOffsetRegexp.FindAllStringSubmatchIndex(str, -1) {
+			indexes = append(indexes, submatchIndex[2:4])
+		}
+		sort.Slice(indexes, func(i, j int) bool { return indexes[i][0] < indexes[j][0] })
+		return matchFromIndexes(str, indexes)
+	}
+}
+
+// MatchHorizontalWhitespace creates a MatcherFunc that matches runs of whitespace other than line breaks in given string
+func MatchHorizontalWhitespace() MatcherFunc {
+	return func(str string) Match {
+		indexes := findRuneRunIndexes(str, func(r rune) bool { return unicode.IsSpace(r) && r != '\n' && r != '\r' })
+		return matchFromIndexes(str, indexes)
+	}
+}
+
+// MatchInitials creates a MatcherFunc that matches sequences of capital initials like J.R.R. or J. R. R. in given
+// string. Initials which are part of a longer dotted word like Ph.D. are not matched.
+func MatchInitials() MatcherFunc {
+	return func(str string) Match {
+		return matchInitials(str, UpperInitialsRegexp)
+	}
+}
+
+// MatchInitialsAnyCase creates a MatcherFunc that works like MatchInitials but also matches lower case initials like e.e.
+func MatchInitialsAnyCase() MatcherFunc {
+	return func(str string) Match {
+		return matchInitials(str, InitialsRegexp)
+	}
+}
+
+// MatchMarkdownHeaders creates a MatcherFunc that matches ATX (# Title) and setext (Title underlined with === or ---)
+// markdown headers in given string. The title of a setext header is the whole paragraph above the underline, and
+// thematic breaks and list items are not titles. The level and the title of each header are exposed as groups.
+func MatchMarkdownHeaders() MatcherFunc {
+	return func(str string) Match {
+		type header struct {
+			index  []int
+			groups []string
+		}
+		var headers []header
+		for _, submatchIndex := range ATXHeaderRegexp.FindAllStringSubmatchIndex(str, -1) {
+			submatches := submatchStrings(str, submatchIndex[2:])
+			level := strconv.Itoa(len(submatches[0]))
+			headers = append(headers, header{submatchIndex[:2], []string{level, submatches[1]}})
+		}
+		for _, submatchIndex := range SetextHeaderRegexp.FindAllStringSubmatchIndex(str, -1) {
+			start, ok := setextTitleStart(str, submatchIndex[0])
+			if !ok {
+				continue
+			}
+			level := "1"
+			if str[submatchIndex[4]] == '-' {
+				level = "2"
+			}
+			titleLines := strings.Split(str[start:submatchIndex[3]], "\n")
+			for i := range titleLines {
+				titleLines[i] = strings.TrimSpace(titleLines[i])
+			}
+			headers = append(headers, header{[]int{start, submatchIndex[1]}, []string{level, strings.Join(titleLines, "\n")}})
+		}
+		sort.Slice(headers, func(i, j int) bool { return headers[i].index[0] < headers[j].index[0] })
+
+		var indexes [][]int
+		var groups [][]string
+		for _, h := range headers {
+			indexes = append(indexes, h.index)
+			groups = append(groups, h.groups)
+		}
+		match := matchFromIndexes(str, indexes)
+		match.Groups = groups
+		return match
+	}
+}
+
+// MatchRepeatedPunctuation creates a MatcherFunc that matches runs of two or more sentence punctuation marks like !!!,
+// ?!?! or .... in given string
+func MatchRepeatedPunctuation() MatcherFunc {
+	return func(str string) Match {
+		return MatchRegexp(RepeatedPunctuationRegexp)(str)
+	}
+}
+
+// MatchNucleotideRuns creates a MatcherFunc that matches case-insensitively the maximal runs of at least minLen runes
+// from given alphabet, like ACGT for DNA or ACGU for RNA, in given string. Runs which are part of a longer word, like
+// the tact of tactic, are not matched.
+func MatchNucleotideRuns(alphabet string, minLen int) MatcherFunc {
+	symbols := []rune(strings.ToUpper(alphabet))
+	return func(str string) Match {
+		var indexes [][]int
+		for _, index := range findRuneRunIndexes(str, isWordRune) {
+			word := str[index[0]:index[1]]
+			if utf8.RuneCountInString(word) < minLen {
+				continue
+			}
+			if strings.IndexFunc(word, func(r rune) bool { return !containsRune(symbols, unicode.ToUpper(r)) }) < 0 {
+				indexes = append(indexes, index)
+			}
+		}
+		return matchFromIndexes(str, indexes)
+	}
+}
+
+// MatchTableRows creates a MatcherFunc that matches the cells of pipe-delimited markdown and ASCII table rows like
+// | a | b | in given string, leaving the pipes and the padding of the cells unmatched. Separator rows like |---|:--:|,
+// border lines like +---+---+ and empty cells are not matched. The row and column number of each cell, counting from 1
+// in each table with the separator row not counted, are exposed as groups.
+func MatchTableRows() MatcherFunc {
+	return func(str string) Match {
+		var indexes [][]int
+		var groups [][]string
+		row := 0
+		for lineStart := 0; lineStart < len(str); {
+			lineEnd := strings.IndexByte(str[lineStart:], '\n')
+			if lineEnd < 0 {
+				lineEnd = len(str)
+			} else {
+				lineEnd += lineStart
+			}
+			cells := findTableCells(str, lineStart, lineEnd)
+			switch {
+			case cells == nil && TableBorderRegexp.MatchString(str[lineStart:lineEnd]):
+			case cells == nil:
+				row = 0
+			case !isTableSeparator(str, cells):
+				row++
+				for column, cell := range cells {
+					if cell[0] < cell[1] {
+						indexes = append(indexes, cell)
+						groups = append(groups, []string{strconv.Itoa(row), strconv.Itoa(column + 1)})
+					}
+				}
+			}
+			lineStart = lineEnd + 1
+		}
+		match := matchFromIndexes(str, indexes)
+		match.Groups = groups
+		return match
+	}
+}
+
+// MatchChangelogHeaders creates a MatcherFunc that matches changelog release header lines like ## [1.2.3] - 2023-01-02,
+// v1.2.3 (2023-01-02) or ## [Unreleased] in given string. The version, without a leading v, and the date of each
+// header are exposed as groups, the date group is empty for headers without a date.
+func MatchChangelogHeaders() MatcherFunc {
+	return func(str string) Match {
+		var indexes [][]int
+		var groups [][]string
+		for _, submatchIndex := range ChangelogHeaderRegexp.FindAllStringSubmatchIndex(str, -1) {
+			header := strings.TrimRight(str[submatchIndex[0]:submatchIndex[1]], " \t")
+			indexes = append(indexes, []int{submatchIndex[0], submatchIndex[0] + len(header)})
+			submatches := submatchStrings(str, submatchIndex[2:])
+			version := submatches[0] + submatches[1] + submatches[2]
+			date := submatches[3] + submatches[4]
+			groups = append(groups, []string{version, date})
+		}
+		match := matchFromIndexes(str, indexes)
+		match.Groups = groups
+		return match
+	}
+}
+
+// RangeSeparators are the separators of numeric ranges matched by MatchRanges
+var RangeSeparators = []string{"..", "-", "–", " to "}
+
+// MatchRanges creates a MatcherFunc that matches numeric ranges like 5-10, 2..8 or 1 to 5 in given string whose low end
+// is not greater than the high end. The low and high ends of each range are exposed as groups. Ranges chained with
+// further separated numbers, like dates, and ranges following a minus sign or a letter are not matched.
+func MatchRanges() MatcherFunc {
+	return MatchRangesWith(RangeSeparators)
+}
+
+// MatchRangesWith creates a MatcherFunc that works like MatchRanges but matches the ranges with given separators
+func MatchRangesWith(separators []string) MatcherFunc {
+	sorted := append([]string(nil), separators...)
+	sort.SliceStable(sorted, func(i, j int) bool { return len(sorted[i]) > len(sorted[j]) })
+	quoted := make([]string, len(sorted))
+	for i, separator := range sorted {
+		quoted[i] = regexp.QuoteMeta(separator)
+	}
+	separator := strings.Join(quoted, "|")
+	rangeRegexp := regexp.MustCompile(fmt.Sprintf(`(\d+(?:\.\d+)?)(?:%s)(\d+(?:\.\d+)?)`, separator))
+	chainRegexp := regexp.MustCompile(fmt.Sprintf(`^(?:%s)\d`, separator))
+	return func(str string) Match {
+		if len(separators) == 0 {
+			return matchFromIndexes(str, nil)
+		}
+		var indexes [][]int
+		var groups [][]string
+		for _, submatchIndex := range rangeRegexp.FindAllStringSubmatchIndex(str, -1) {
+			start, end := submatchIndex[0], submatchIndex[1]
+			if before, _ := utf8.DecodeLastRuneInString(str[:start]); start > 0 && (isWordRune(before) || before == '.' || before == '-') {
+				continue
+			}
+			if after, _ := utf8.DecodeRuneInString(str[end:]); (end < len(str) && isWordRune(after)) || chainRegexp.MatchString(str[end:]) {
+				continue
+			}
+			submatches := submatchStrings(str, submatchIndex[2:])
+			low, _ := strconv.ParseFloat(submatches[0], 64)
+			high, _ := strconv.ParseFloat(submatches[1], 64)
+			if low > high {
+				continue
+			}
+			indexes = append(indexes, submatchIndex[:2])
+			groups = append(groups, submatches)
+		}
+		match := matchFromIndexes(str, indexes)
+		match.Groups = groups
+		return match
+	}
+}
+
+// MatchBraceGroups creates a MatcherFunc that matches shell brace expansion groups like {jpg,png,gif} or {1..5} in given
+// string. Nested groups like {a,{b,c}} are matched as part of their outermost group. The top level alternatives of comma
+// groups and the bounds, with the optional increment, of range groups are exposed as groups. Braces without a top level
+// comma or range, like {} or ${var}, are not matched.
+func MatchBraceGroups() MatcherFunc {
+	return func(str string) Match {
+		var open []int
+		var closed [][]int
+		for i := 0; i < len(str); i++ {
+			switch str[i] {
+			case '\\':
+				i++
+			case '{':
+				open = append(open, i)
+			case '}':
+				if len(open) == 0 {
+					continue
+				}
+				start := open[len(open)-1]
+				open = open[:len(open)-1]
+				if (start == 0 || str[start-1] != '$') && braceAlternatives(str[start+1:i]) != nil {
+					closed = append(closed, []int{start, i + 1})
+				}
+			}
+		}
+		indexes := outermostIndexes(closed)
+		groups := make([][]string, len(indexes))
+		for i, index := range indexes {
+			groups[i] = braceAlternatives(str[index[0]+1 : index[1]-1])
+		}
+		match := matchFromIndexes(str, indexes)
+		if len(indexes) > 0 {
+			match.Groups = groups
+		}
+		return match
+	}
+}
+
+// MatchFuzzy creates a MatcherFunc that matches words within the Levenshtein distance maxDist of given word,
+// case-insensitively, in given string. Words whose length differs by more than maxDist are skipped without computing the
+// distance and the computation of each distance is cut short once it exceeds maxDist, so matching takes
+// O(len(str) * maxDist) time.
+func MatchFuzzy(word string, maxDist int) MatcherFunc {
+	target := []rune(strings.ToLower(word))
+	return func(str string) Match {
+		var indexes [][]int
+		for _, index := range findRuneRunIndexes(str, isWordRune) {
+			candidate := []rune(strings.ToLower(str[index[0]:index[1]]))
+			if abs(len(candidate)-len(target)) > maxDist {
+				continue
+			}
+			if withinDistance(candidate, target, maxDist) {
+				indexes = append(indexes, index)
+			}
+		}
+		return matchFromIndexes(str, indexes)
+	}
+}
+
+// MatchUnicodeEscapes creates a MatcherFunc that matches \uXXXX and \UXXXXXXXX code point escapes in given string. A
+// UTF-16 surrogate pair like \uD83D\uDE00 is matched as a single pattern.
+func MatchUnicodeEscapes() MatcherFunc {
+	return func(str string) Match {
+		return MatchRegexp(UnicodeEscapeRegexp)(str)
+	}
+}
+
+// DecodeUnicodeEscape returns the character represented by given pattern of MatchUnicodeEscapes, or the pattern itself
+// if it is not a valid code point like a lone surrogate. It is meant to be used with Match.Map.
+func DecodeUnicodeEscape(escape string) string {
+	var runes []rune
+	for _, hex := range strings.FieldsFunc(escape, func(r rune) bool { return r == '\\' || r == 'u' || r == 'U' }) {
+		codePoint, err := strconv.ParseUint(hex, 16, 32)
+		if err != nil {
+			return escape
+		}
+		runes = append(runes, rune(codePoint))
+	}
+	if len(runes) == 2 {
+		runes = []rune{utf16.DecodeRune(runes[0], runes[1])}
+	}
+	if len(runes) != 1 || !utf8.ValidRune(runes[0]) {
+		return escape
+	}
+	return string(runes)
+}
+
+// MatchNumbersLocale creates a MatcherFunc that matches numbers written with given digit group and decimal separators,
+// like 1,234,567.89 with ',' and '.' or 1.234.567,89 with '.' and ',', in given string. Digit groups must have exactly
+// three digits, so with ',' as group separator 1,234 is one number while 1,23 is two.
+func MatchNumbersLocale(groupSep, decimalSep rune) MatcherFunc {
+	group := regexp.QuoteMeta(string(groupSep))
+	decimal := regexp.QuoteMeta(string(decimalSep))
+	numberRegexp := regexp.MustCompile(fmt.Sprintf(`[-+]?(?:\d{1,3}(?:%s\d{3})+\b|\d+)(?:%s\d+)?`, group, decimal))
+	return func(str string) Match {
+		return MatchRegexp(numberRegexp)(str)
+	}
+}
+
+// MatchPhoneNumbers creates a MatcherFunc that matches phone numbers like +1 (555) 123-4567, 555.123.4567 or
+// +44 20 7946 0958 with an optional extension like x123 or ext. 456 in given string. The country code, area code,
+// subscriber number and extension of each phone number are exposed as groups, missing ones are empty.
+func MatchPhoneNumbers() MatcherFunc {
+	return func(str string) Match {
+		var indexes [][]int
+		var groups [][]string
+		for _, submatchIndex := range PhoneRegexp.FindAllStringSubmatchIndex(str, -1) {
+			start, end := submatchIndex[0], submatchIndex[1]
+			before, _ := utf8.DecodeLastRuneInString(str[:start])
+			after, _ := utf8.DecodeRuneInString(str[end:])
+			if (start > 0 && isWordRune(before)) || (end < len(str) && isWordRune(after)) {
+				continue
+			}
+			submatches := submatchStrings(str, submatchIndex[2:])
+			indexes = append(indexes, submatchIndex[:2])
+			groups = append(groups, []string{submatches[0], submatches[1] + submatches[2], submatches[3], submatches[4]})
+		}
+		match := matchFromIndexes(str, indexes)
+		match.Groups = groups
+		return match
+	}
+}
+
+// MatchConfusables creates a MatcherFunc that matches substrings of given string which look like given target but are
+// spelled with confusable characters, like the Cyrillic а of раypal for paypal. Both are compared case-insensitively
+// after mapping each rune through a small table of Cyrillic, Greek and digit homoglyphs of Latin letters. Substrings
+// spelled exactly like the target, ignoring case, are not matched.
+func MatchConfusables(target string) MatcherFunc {
+	skeleton := strings.Map(confusableSkeleton, target)
+	return func(str string) Match {
+		if skeleton == "" {
+			return matchFromIndexes(str, nil)
+		}
+		match := matchMapped(str, MatchAll(skeleton), confusableSkeleton)
+		return filterMatch(match, func(_ int, index []int) bool {
+			return !strings.EqualFold(str[index[0]:index[1]], target)
+		})
+	}
+}
+
+// MatchPhrase creates a MatcherFunc that matches given words in sequence separated by any amount of whitespace, including
+// line breaks, in given string. Phrases which are part of a longer word at either end, like New Yorker for New and York,
+// are not matched.
+func MatchPhrase(words ...string) MatcherFunc {
+	quoted := make([]string, len(words))
+	for i, word := range words {
+		quoted[i] = regexp.QuoteMeta(word)
+	}
+	phraseRegexp := regexp.MustCompile(strings.Join(quoted, `\s+`))
+	return func(str string) Match {
+		if len(words) == 0 {
+			return matchFromIndexes(str, nil)
+		}
+		var indexes [][]int
+		for _, index := range phraseRegexp.FindAllStringIndex(str, -1) {
+			first, _ := utf8.DecodeRuneInString(str[index[0]:])
+			before, _ := utf8.DecodeLastRuneInString(str[:index[0]])
+			last, _ := utf8.DecodeLastRuneInString(str[:index[1]])
+			after, _ := utf8.DecodeRuneInString(str[index[1]:])
+			if (index[0] > 0 && isWordRune(first) && isWordRune(before)) ||
+				(index[1] < len(str) && isWordRune(last) && isWordRune(after)) {
+				continue
+			}
+			indexes = append(indexes, index)
+		}
+		return matchFromIndexes(str, indexes)
+	}
+}
+
+// MatchScientific creates a MatcherFunc that matches numbers in scientific notation like 1.23e-4 or 6.022E23 in given
+// string. Plain numbers without an exponent are not matched. The mantissa and the exponent of each number are exposed as
+// groups.
+func MatchScientific() MatcherFunc {
+	return func(str string) Match {
+		var indexes [][]int
+		var groups [][]string
+		for _, submatchIndex := range ScientificNumberRegexp.FindAllStringSubmatchIndex(str, -1) {
+			start, end := submatchIndex[0], submatchIndex[1]
+			before, _ := utf8.DecodeLastRuneInString(str[:start])
+			after, _ := utf8.DecodeRuneInString(str[end:])
+			if (start > 0 && (isWordRune(before) || before == '.')) || (end < len(str) && isWordRune(after)) {
+				continue
+			}
+			indexes = append(indexes, submatchIndex[:2])
+			groups = append(groups, submatchStrings(str, submatchIndex[2:]))
+		}
+		match := matchFromIndexes(str, indexes)
+		match.Groups = groups
+		return match
+	}
+}
+
+// LogLevels maps the log level tokens matched by MatchLogLevels to their labels
+var LogLevels = map[string]string{
+	"TRACE":   "trace",
+	"DEBUG":   "debug",
+	"INFO":    "info",
+	"WARN":    "warn",
+	"WARNING": "warn",
+	"ERROR":   "error",
+	"FATAL":   "fatal",
+}
+
+// MatchLogLevels creates a MatcherFunc that matches the log level tokens of LogLevels as whole words, case-insensitively,
+// in given string and labels each with its level
+func MatchLogLevels() MatcherFunc {
+	return MatchLogLevelsWith(LogLevels)
+}
+
+// MatchLogLevelsWith creates a MatcherFunc that works like MatchLogLevels but matches the tokens of given map of tokens
+// to labels
+func MatchLogLevelsWith(levels map[string]string) MatcherFunc {
+	labels := make(map[string]string, len(levels))
+	tokens := make([]string, 0, len(levels))
+	for token, label := range levels {
+		labels[strings.ToLower(token)] = label
+		tokens = append(tokens, regexp.QuoteMeta(token))
+	}
+	sort.Strings(tokens)
+	levelRegexp := regexp.MustCompile(fmt.Sprintf(`(?i)\b(?:%s)\b`, strings.Join(tokens, "|")))
+	return func(str string) Match {
+		if len(levels) == 0 {
+			return matchFromIndexes(str, nil)
+		}
+		match := MatchRegexp(levelRegexp)(str)
+		if len(match.Patterns) > 0 {
+			match.Labels = make([]string, len(match.Patterns))
+			for i, pattern := range match.Patterns {
+				match.Labels[i] = labels[strings.ToLower(pattern)]
+			}
+		}
+		return match
+	}
+}
+
+// Contractions maps the lower case English contractions matched by MatchContractions to their expansions. Ambiguous
+// contractions are mapped to their most common expansion, like it's to it is rather than it has.
+var Contractions = map[string]string{
+	"ain't": "am not", "aren't": "are not", "can't": "cannot", "couldn't": "could not", "could've": "could have",
+	"didn't": "did not", "doesn't": "does not", "don't": "do not", "hadn't": "had not", "hasn't": "has not",
+	"haven't": "have not", "he'd": "he would", "he'll": "he will", "he's": "he is", "here's": "here is", "i'd": "I would",
+	"i'll": "I will", "i'm": "I am", "i've": "I have", "isn't": "is not", "it'd": "it would", "it'll": "it will",
+	"it's": "it is", "let's": "let us", "mightn't": "might not", "might've": "might have", "mustn't": "must not",
+	"must've": "must have", "shan't": "shall not", "she'd": "she would", "she'll": "she will", "she's": "she is",
+	"shouldn't": "should not", "should've": "should have", "that's": "that is", "there's": "there is",
+	"they'd": "they would", "they'll": "they will", "they're": "they are", "they've": "they have", "wasn't": "was not",
+	"we'd": "we would", "we'll": "we will", "we're": "we are", "we've": "we have", "weren't": "were not",
+	"what's": "what is", "where's": "where is", "who's": "who is", "won't": "will not", "wouldn't": "would not",
+	"would've": "would have", "y'all": "you all", "you'd": "you would", "you'll": "you will", "you're": "you are",
+	"you've": "you have",
+}
+
+// MatchContractions creates a MatcherFunc that matches the English contractions of Contractions as whole words in given
+// string, case-insensitively and with either straight (') or curly (’) apostrophes. Possessives like John's and words
+// like its are not matched. The expansion of each contraction, capitalized like the contraction, is exposed as a group.
+func MatchContractions() MatcherFunc {
+	return func(str string) Match {
+		var indexes [][]int
+		var groups [][]string
+		for _, index := range WordRegexp.FindAllStringIndex(str, -1) {
+			if expansion := ExpandContraction(str[index[0]:index[1]]); expansion != str[index[0]:index[1]] {
+				indexes = append(indexes, index)
+				groups = append(groups, []string{expansion})
+			}
+		}
+		match := matchFromIndexes(str, indexes)
+		match.Groups = groups
+		return match
+	}
+}
+
+// ExpandContraction returns the expansion of given contraction from Contractions, capitalized like the contraction, or
+// the contraction itself if it is not known. It is meant to be used with Match.Map.
+func ExpandContraction(contraction string) string {
+	expansion, ok := Contractions[strings.ToLower(strings.ReplaceAll(contraction, "’", "'"))]
+	if !ok {
+		return contraction
+	}
+	switch first, _ := utf8.DecodeRuneInString(contraction); {
+	case contraction == strings.ToUpper(contraction) && len(contraction) > 2:
+		return strings.ToUpper(expansion)
+	case unicode.IsUpper(first):
+		expansionFirst, size := utf8.DecodeRuneInString(expansion)
+		return string(unicode.ToUpper(expansionFirst)) + expansion[size:]
+	}
+	return expansion
+}
+
+// MatchRepeated creates a MatcherFunc that matches runs of at least min consecutive occurrences of given substring in
+// given string, like ababab for ab. Occurrences do not overlap, so aaa is a single occurrence of aa followed by a. The
+// number of occurrences in each run is exposed as its count.
+func MatchRepeated(sub string, min int) MatcherFunc {
+	return func(str string) Match {
+		var indexes [][]int
+		var counts []int
+		for i := 0; sub != "" && i < len(str); {
+			start := strings.Index(str[i:], sub)
+			if start < 0 {
+				break
+			}
+			start += i
+			end, count := start, 0
+			for strings.HasPrefix(str[end:], sub) {
+				end += len(sub)
+				count++
+			}
+			if count < min {
+				i = start + 1
+				continue
+			}
+			indexes = append(indexes, []int{start, end})
+			counts = append(counts, count)
+			i = end
+		}
+		match := matchFromIndexes(str, indexes)
+		match.Counts = counts
+		return match
+	}
+}
+
+// SPDXLicenses are the SPDX license identifiers matched by MatchSPDX
+var SPDXLicenses = []string{
+	"0BSD", "AGPL-3.0", "AGPL-3.0-only", "AGPL-3.0-or-later", "Apache-1.1", "Apache-2.0", "Artistic-2.0",
+	"BSD-1-Clause", "BSD-2-Clause", "BSD-3-Clause", "BSD-4-Clause", "BSL-1.0", "CC-BY-4.0", "CC-BY-SA-4.0", "CC0-1.0",
+	"CDDL-1.0", "EPL-1.0", "EPL-2.0", "EUPL-1.2", "GPL-2.0", "GPL-2.0-only", "GPL-2.0-or-later", "GPL-3.0",
+	"GPL-3.0-only", "GPL-3.0-or-later", "ISC", "LGPL-2.1", "LGPL-2.1-only", "LGPL-2.1-or-later", "LGPL-3.0",
+	"LGPL-3.0-only", "LGPL-3.0-or-later", "MIT", "MIT-0", "MPL-2.0", "MS-PL", "NCSA", "OFL-1.1", "PostgreSQL",
+	"Python-2.0", "Unicode-DFS-2016", "Unlicense", "UPL-1.0", "WTFPL", "X11", "Zlib",
+}
+
+// SPDXExceptions are the SPDX license exception identifiers matched by MatchSPDX after WITH
+var SPDXExceptions = []string{
+	"Autoconf-exception-3.0", "Bison-exception-2.2", "Classpath-exception-2.0", "GCC-exception-3.1",
+	"LLVM-exception", "OpenJDK-assembly-exception-1.0", "Qt-LGPL-exception-1.1", "Universal-FOSS-exception-1.0",
+}
+
+// MatchSPDX creates a MatcherFunc that matches SPDX license identifiers of SPDXLicenses like MIT or GPL-3.0-or-later and
+// license expressions combining them with AND, OR and WITH an exception of SPDXExceptions, like MIT OR Apache-2.0, in
+// given string. Identifiers are compared case-insensitively and unknown identifiers are not matched.
+func MatchSPDX() MatcherFunc {
+	return MatchSPDXWith(SPDXLicenses, SPDXExceptions)
+}
+
+// MatchSPDXWith creates a MatcherFunc that works like MatchSPDX but matches given license and exception identifiers
+func MatchSPDXWith(licenses, exceptions []string) MatcherFunc {
+	isLicense := newFoldSet(licenses)
+	isException := newFoldSet(exceptions)
+	return func(str string) Match {
+		tokens := SPDXTokenRegexp.FindAllStringIndex(str, -1)
+		token := func(i int) string { return str[tokens[i][0]:tokens[i][1]] }
+		// continues reports whether the token following the i-th one is an operator combining it with the next token
+		continues := func(i int) bool {
+			if i+2 >= len(tokens) || strings.TrimSpace(str[tokens[i][1]:tokens[i+1][0]]) != "" ||
+				strings.TrimSpace(str[tokens[i+1][1]:tokens[i+2][0]]) != "" {
+				return false
+			}
+			switch token(i + 1) {
+			case "AND", "OR":
+				return isLicense(strings.TrimSuffix(token(i+2), "+"))
+			case "WITH":
+				return isException(token(i + 2))
+			}
+			return false
+		}
+
+		var indexes [][]int
+		for i := 0; i < len(tokens); i++ {
+			if !isLicense(strings.TrimSuffix(token(i), "+")) {
+				continue
+			}
+			start := tokens[i][0]
+			for continues(i) {
+				i += 2
+			}
+			indexes = append(indexes, []int{start, tokens[i][1]})
+		}
+		return matchFromIndexes(str, indexes)
+	}
+}
+
+// MatchRepeatedNgrams creates a MatcherFunc that matches all occurrences of the sequences of n words occurring at least
+// minOccurrences times, case-insensitively, in given string. Overlapping or adjacent occurrences, like the ones of a
+// repeated phrase longer than n words, are merged into one pattern.
+func MatchRepeatedNgrams(n, minOccurrences int) MatcherFunc {
+	return func(str string) Match {
+		words := WordRegexp.FindAllStringIndex(str, -1)
+		if n <= 0 || len(words) < n {
+			return matchFromIndexes(str, nil)
+		}
+		keys := make([]string, len(words)-n+1)
+		occurrences := make(map[string]int)
+		for i := range keys {
+			ngram := make([]string, n)
+			for j, word := range words[i : i+n] {
+				ngram[j] = strings.ToLower(str[word[0]:word[1]])
+			}
+			keys[i] = strings.Join(ngram, " ")
+			occurrences[keys[i]]++
+		}
+
+		var indexes [][]int
+		for i, key := range keys {
+			if occurrences[key] < minOccurrences {
+				continue
+			}
+			start, end := words[i][0], words[i+n-1][1]
+			if last := len(indexes) - 1; last >= 0 && start <= indexes[last][1] {
+				indexes[last][1] = end
+				continue
+			}
+			indexes = append(indexes, []int{start, end})
+		}
+		return matchFromIndexes(str, indexes)
+	}
+}
+
+// MatchPostalCode creates a MatcherFunc that matches the postal codes of given country in given string, like 12345 or
+// 12345-6789 for US, SW1A 1AA for UK and K1A 0B1 for CA. Countries without an entry in PostalCodeRegexps match nothing.
+// Any five-digit number is matched as a US ZIP code.
+func MatchPostalCode(country string) MatcherFunc {
+	return func(str string) Match {
+		r, ok := PostalCodeRegexps[strings.ToUpper(country)]
+		if !ok {
+			return matchFromIndexes(str, nil)
+		}
+		return MatchRegexp(r)(str)
+	}
+}
+
+// MatchPostalCodeAny creates a MatcherFunc that matches the postal codes of all countries in PostalCodeRegexps in given
+// string and labels each with its country. When postal codes overlap the leftmost one wins, on equal start the one of
+// the country whose code sorts first wins.
+func MatchPostalCodeAny() MatcherFunc {
+	return func(str string) Match {
+		countries := make([]string, 0, len(PostalCodeRegexps))
+		for country := range PostalCodeRegexps {
+			countries = append(countries, country)
+		}
+		sort.Strings(countries)
+
+		var candidates []capture
+		sourceCountries := make(map[*Match]string, len(countries))
+		for _, country := range countries {
+			match := MatchRegexp(PostalCodeRegexps[country])(str)
+			_, matchCaptures := captures(&match)
+			candidates = append(candidates, matchCaptures...)
+			sourceCountries[&match] = country
+		}
+		resolved := resolveOverlaps(candidates)
+		match := buildMatch(str, resolved)
+		for _, c := range resolved {
+			match.Labels = append(match.Labels, sourceCountries[c.source])
+		}
+		return match
+	}
+}
+
+// MatchDataURIs creates a MatcherFunc that matches data URIs like data:image/png;base64,iVBORw0KGgo= in given string.
+// The media type with its parameters, "base64" for base64 encoded payloads and the payload of each URI are exposed as
+// groups, missing ones are empty. Data URIs can be very long, use MaxPatternLength to leave the longer ones unmatched.
+func MatchDataURIs() MatcherFunc {
+	return func(str string) Match {
+		var indexes [][]int
+		var groups [][]string
+		for _, submatchIndex := range DataURIRegexp.FindAllStringSubmatchIndex(str, -1) {
+			submatches := submatchStrings(str, submatchIndex[2:])
+			indexes = append(indexes, submatchIndex[:2])
+			groups = append(groups, []string{submatches[0], strings.TrimPrefix(submatches[1], ";"), submatches[2]})
+		}
+		match := matchFromIndexes(str, indexes)
+		match.Groups = groups
+		return match
+	}
+}
+
+// MatchWikiLinks creates a MatcherFunc that matches [[Target]] and [[Target|Display]] wiki links in given string. The
+// target and the display label of each link are exposed as groups, the label is empty for links without one.
+func MatchWikiLinks() MatcherFunc {
+	return func(str string) Match {
+		var indexes [][]int
+		var groups [][]string
+		for _, submatchIndex := range WikiLinkRegexp.FindAllStringSubmatchIndex(str, -1) {
+			indexes = append(indexes, submatchIndex[:2])
+			groups = append(groups, submatchStrings(str, submatchIndex[2:]))
+		}
+		match := matchFromIndexes(str, indexes)
+		match.Groups = groups
+		return match
+	}
+}
+
+// MatchSSN creates a MatcherFunc that matches US social security numbers like 123-45-6789 or 123456789 in given string,
+// rejecting the ones which are never assigned: area 000, 666 or 900-999, group 00 or serial 0000
+func MatchSSN() MatcherFunc {
+	return func(str string) Match {
+		return matchSSN(str, true)
+	}
+}
+
+// MatchSSNLoose creates a MatcherFunc that works like MatchSSN but matches any number of the same shape
+func MatchSSNLoose() MatcherFunc {
+	return func(str string) Match {
+		return matchSSN(str, false)
+	}
+}
+
+// MatchICUPlaceholders creates a MatcherFunc that matches ICU MessageFormat placeholders like {name}, {0, number} or
+// {count, plural, one {# item} other {# items}} in given string, including their nested messages. Braces quoted with
+// apostrophes like '{' are skipped and unclosed placeholders are left unmatched.
+func MatchICUPlaceholders() MatcherFunc {
+	return func(str string) Match {
+		var indexes [][]int
+		start, depth := -1, 0
+		for i := 0; i < len(str); i++ {
+			switch str[i] {
+			case '\'':
+				if i+1 < len(str) && (str[i+1] == '{' || str[i+1] == '}') {
+					if closing := strings.IndexByte(str[i+1:], '\''); closing >= 0 {
+						i += closing + 1
+					}
+				}
+			case '{':
+				if depth == 0 {
+					if !ICUArgumentRegexp.MatchString(str[i:]) {
+						continue
+					}
+					start = i
+				}
+				depth++
+			case '}':
+				if depth == 0 {
+					continue
+				}
+				depth--
+				if depth == 0 {
+					indexes = append(indexes, []int{start, i + 1})
+				}
+			}
+		}
+		return matchFromIndexes(str, indexes)
+	}
+}
+
+// MatchTripledChars creates a MatcherFunc that matches runs of a character repeated three or more times, like the ooo of
+// loooong, in given string
+func MatchTripledChars() MatcherFunc {
+	return MatchCharRuns(3)
+}
+
+// MatchCharRuns creates a MatcherFunc that matches runs of a character repeated at least minRun times in given string
+func MatchCharRuns(minRun int) MatcherFunc {
+	return func(str string) Match {
+		var indexes [][]int
+		for runStart := 0; runStart < len(str); {
+			_, size := utf8.DecodeRuneInString(str[runStart:])
+			runEnd, runLen := runStart+size, 1
+			for strings.HasPrefix(str[runEnd:], str[runStart:runStart+size]) {
+				runEnd += size
+				runLen++
+			}
+			if runLen >= minRun {
+				indexes = append(indexes, []int{runStart, runEnd})
+			}
+			runStart = runEnd
+		}
+		return matchFromIndexes(str, indexes)
+	}
+}
+
+// ReduceRun returns a function which cuts a run of a character matched by MatchCharRuns down to n characters. It is
+// meant to be used with Match.Map.
+func ReduceRun(n int) func(string) string {
+	return func(run string) string {
+		r, _ := utf8.DecodeRuneInString(run)
+		return strings.Repeat(string(r), min(n, utf8.RuneCountInString(run)))
+	}
+}
+
+// MatchWordsByFrequency counts the words of given corpus case-insensitively and returns a MatcherFunc matching the rare
+// words, occurring at most rareMax times in the corpus, and a MatcherFunc matching the common words, occurring at least
+// commonMin times in the corpus. Words missing from the corpus occur 0 times.
+func MatchWordsByFrequency(corpus string, rareMax, commonMin int) (rare MatcherFunc, common MatcherFunc) {
+	frequencies := make(map[string]int)
+	for _, word := range WordRegexp.FindAllString(corpus, -1) {
+		frequencies[strings.ToLower(word)]++
+	}
+	matchFrequency := func(keep func(int) bool) MatcherFunc {
+		return func(str string) Match {
+			var indexes [][]int
+			for _, index := range WordRegexp.FindAllStringIndex(str, -1) {
+				if keep(frequencies[strings.ToLower(str[index[0]:index[1]])]) {
+					indexes = append(indexes, index)
+				}
+			}
+			return matchFromIndexes(str, indexes)
+		}
+	}
+	rare = matchFrequency(func(frequency int) bool { return frequency <= rareMax })
+	common = matchFrequency(func(frequency int) bool { return frequency >= commonMin })
+	return rare, common
+}
+
+// Arrows are the ASCII arrows matched by MatchArrows
+var Arrows = []string{"->", "=>", "<-", "<->", "-->", "==>"}
+
+// MatchArrows creates a MatcherFunc that matches the ASCII arrows of Arrows in given string as whole tokens, so the
+// longest arrow is matched and runs of arrow characters which are not an arrow, like --->, are not matched at all
+func MatchArrows() MatcherFunc {
+	return MatchArrowsWith(Arrows)
+}
+
+// MatchArrowsWith creates a MatcherFunc that works like MatchArrows but matches given arrows
+func MatchArrowsWith(arrows []string) MatcherFunc {
+	isArrow := make(map[string]bool, len(arrows))
+	var arrowRunes []rune
+	for _, arrow := range arrows {
+		isArrow[arrow] = true
+		for _, r := range arrow {
+			if !containsRune(arrowRunes, r) {
+				arrowRunes = append(arrowRunes, r)
+			}
+		}
+	}
+	return func(str string) Match {
+		var indexes [][]int
+		for _, index := range findRuneRunIndexes(str, func(r rune) bool { return containsRune(arrowRunes, r) }) {
+			if isArrow[str[index[0]:index[1]]] {
+				indexes = append(indexes, index)
+			}
+		}
+		return matchFromIndexes(str, indexes)
+	}
+}
+
+// MatchHandlebars creates a MatcherFunc that matches the Handlebars tags of given string and labels each with its kind:
+// "comment" for {{! }} and {{!-- --}} comments, "expression" for {{ }} and {{{ }}} expressions and "block" for the
+// {{#name}} and {{/name}} tags of blocks. Since patterns cannot overlap, the opening and closing tag of a block are matched
+// separately with the same depth, the depth of each tag being the number of blocks it is nested in. Block tags without
+// a matching counterpart are left unmatched.
+func MatchHandlebars() MatcherFunc {
+	return func(str string) Match {
+		tags := HandlebarsTagRegexp.FindAllStringSubmatchIndex(str, -1)
+		markers := make([]string, len(tags))
+		paired := make([]bool, len(tags))
+		var open []int
+		for i, tag := range tags {
+			submatches := submatchStrings(str, tag[2:])
+			markers[i] = submatches[0]
+			if markers[i] == "#" {
+				open = append(open, i)
+				continue
+			}
+			for j := len(open) - 1; j >= 0 && markers[i] == "/"; j-- {
+				if submatchStrings(str, tags[open[j]][4:6])[0] == submatches[1] {
+					paired[open[j]], paired[i] = true, true
+					open = open[:j]
+					break
+				}
+			}
+		}
+
+		var indexes [][]int
+		var labels []string
+		var depths []int
+		depth := 0
+		for i, tag := range tags {
+			label := "expression"
+			switch {
+			case strings.HasPrefix(strings.TrimLeft(str[tag[0]+2:tag[1]], "~"), "!"):
+				label = "comment"
+			case markers[i] != "" && !paired[i]:
+				continue
+			case markers[i] != "":
+				label = "block"
+			}
+			if markers[i] == "/" {
+				depth--
+			}
+			indexes = append(indexes, tag[:2])
+			labels = append(labels, label)
+			depths = append(depths, depth)
+			if markers[i] == "#" {
+				depth++
+			}
+		}
+		match := matchFromIndexes(str, indexes)
+		if len(indexes) > 0 {
+			match.Labels = labels
+			match.Depths = depths
+		}
+		return match
+	}
+}
+
+func findPatternMatchIndexes(str string, patternsToMatch []string) map[int]string {
+	patternMatchIndexes := make(map[int]string)
+	pattern := strings.Join(patternsToMatch , "|")
+	patternRegex := regexp.MustCompile(pattern)
+	indices := patternRegex.FindAllStringIndex(str, -1)
+	for _, v := range(indices) {
+		start, end := v[0], v[1]
+		patternMatchIndexes[start] = str[start:end]
+	}
+	return patternMatchIndexes
+}
+
+func getKeys(m map[int]string) []int {
+	keys := make([]int, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func abs(a int) int {
+	if a < 0 {
+		return -a
+	}
+	return a
+}
+
+// findStringIndexes returns the start and end offsets of the first n non-overlapping occurrences of given pattern in
+// given string, or of all of them if n is negative. Like strings.Replace, an empty pattern occurs at the start of the
+// string and after each rune.
+func findStringIndexes(str string, pattern string, n int) [][]int {
+	var indexes [][]int
+	for from := 0; n < 0 || len(indexes) < n; {
+		start := strings.Index(str[from:], pattern)
+		if start < 0 {
+			break
+		}
+		start += from
+		indexes = append(indexes, []int{start, start + len(pattern)})
+		from = start + len(pattern)
+		if pattern == "" {
+			if start == len(str) {
+				break
+			}
+			_, size := utf8.DecodeRuneInString(str[start:])
+			from += size
+		}
+	}
+	return indexes
+}
+
+func findRuneRunIndexes(str string, predicate func(rune) bool) [][]int {
+	var indexes [][]int
+	start := -1
+	for i, r := range str {
+		if predicate(r) {
+			if start < 0 {
+				start = i
+			}
+		} else if start >= 0 {
+			indexes = append(indexes, []int{start, i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		indexes = append(indexes, []int{start, len(str)})
+	}
+	return indexes
+}
+
+func matchFromIndexes(str string, indexes [][]int) Match {
+	var template strings.Builder
+	var patterns []string
+	last := 0
+	for _, index := range indexes {
+		template.WriteString(escapeTemplate(str[last:index[0]]))
+		template.WriteString("%s")
+		patterns = append(patterns, str[index[0]:index[1]])
+		last = index[1]
+	}
+	template.WriteString(escapeTemplate(str[last:]))
+	return Match{Template: template.String(), Patterns: patterns}
+}
+
+func escapeTemplate(str string) string {
+	return strings.ReplaceAll(str, "%", "%%")
+}
+
+func submatchStrings(str string, submatchIndex []int) []string {
+	submatches := make([]string, len(submatchIndex)/2)
+	for i := range submatches {
+		start, end := submatchIndex[2*i], submatchIndex[2*i+1]
+		if start >= 0 {
+			submatches[i] = str[start:end]
+		}
+	}
+	return submatches
+}
+
+func isPalindrome(runes []rune) bool {
+	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+		if runes[i] != runes[j] {
+			return false
+		}
+	}
+	return true
+}
+
+func splitCamelCase(identifier string) []string {
+	var words []string
+	start := 0
+	for i := 1; i < len(identifier); i++ {
+		previous, current := rune(identifier[i-1]), rune(identifier[i])
+		lowerToUpper := !unicode.IsUpper(previous) && unicode.IsUpper(current)
+		acronymEnd := unicode.IsUpper(previous) && unicode.IsUpper(current) &&
+			i+1 < len(identifier) && unicode.IsLower(rune(identifier[i+1]))
+		if lowerToUpper || acronymEnd {
+			words = append(words, identifier[start:i])
+			start = i
+		}
+	}
+	return append(words, identifier[start:])
+}
+
+func matchListMarkers(str string, includeIndent bool) Match {
+	var indexes [][]int
+	for _, submatchIndex := range ListMarkerRegexp.FindAllStringSubmatchIndex(str, -1) {
+		if includeIndent {
+			indexes = append(indexes, []int{submatchIndex[2], submatchIndex[5]})
+		} else {
+			indexes = append(indexes, submatchIndex[4:6])
+		}
+	}
+	return matchFromIndexes(str, indexes)
+}
+
+func matchCSVFields(str string, keepQuotes bool) Match {
+	if str == "" {
+		return matchFromIndexes(str, nil)
+	}
+	var indexes [][]int
+	var groups [][]string
+	for i := 0; i <= len(str); {
+		start, end := i, strings.IndexAny(str[i:], ",\n")
+		if end < 0 {
+			end = len(str)
+		} else {
+			end += i
+		}
+		next := end
+		value := strings.TrimSuffix(str[start:end], "\r")
+		end = start + len(value)
+
+		if i < len(str) && str[i] == '"' {
+			closing := findClosingQuote(str, i+1)
+			if closing >= 0 && (closing+1 == len(str) || strings.IndexByte(",\r\n", str[closing+1]) >= 0) {
+				start, end = i+1, closing
+				value = strings.ReplaceAll(str[start:end], `""`, `"`)
+				if keepQuotes {
+					start, end = i, closing+1
+				}
+				next = closing + 1
+				if next < len(str) && str[next] == '\r' {
+					next++
+				}
+			}
+		}
+
+		indexes = append(indexes, []int{start, end})
+		groups = append(groups, []string{value})
+		if next >= len(str) || (str[next] == '\n' && next+1 == len(str)) {
+			break
+		}
+		i = next + 1
+	}
+	match := matchFromIndexes(str, indexes)
+	match.Groups = groups
+	return match
+}
+
+func findClosingQuote(str string, from int) int {
+	for i := from; i < len(str); i++ {
+		if str[i] != '"' {
+			continue
+		}
+		if i+1 < len(str) && str[i+1] == '"' {
+			i++
+			continue
+		}
+		return i
+	}
+	return -1
+}
+
+func matchAnagrams(str string, word string, includeWord bool) Match {
+	lowerWord := strings.ToLower(word)
+	signature := letterSignature(lowerWord)
+	var indexes [][]int
+	for _, index := range findRuneRunIndexes(str, unicode.IsLetter) {
+		candidate := strings.ToLower(str[index[0]:index[1]])
+		if !includeWord && candidate == lowerWord {
+			continue
+		}
+		if letterSignature(candidate) == signature {
+			indexes = append(indexes, index)
+		}
+	}
+	return matchFromIndexes(str, indexes)
+}
+
+func letterSignature(word string) string {
+	runes := []rune(word)
+	sort.Slice(runes, func(i, j int) bool { return runes[i] < runes[j] })
+	return string(runes)
+}
+
+func containsRune(runes []rune, r rune) bool {
+	for _, candidate := range runes {
+		if candidate == r {
+			return true
+		}
+	}
+	return false
+}
+
+const globCharacter = `[^\s()\[\]{}<>"',;]`
+
+func globToRegexp(pattern string) string {
+	var r strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '*':
+			r.WriteString(globCharacter + "*")
+		case '?':
+			r.WriteString(globCharacter)
+		case '[':
+			closing := findGlobClassEnd(pattern, i)
+			if closing < 0 {
+				r.WriteString(regexp.QuoteMeta("["))
+				continue
+			}
+			class := pattern[i+1 : closing]
+			negation := ""
+			if class[0] == '!' {
+				negation, class = "^", class[1:]
+			}
+			class = strings.ReplaceAll(class, `\`, `\\`)
+			if class[0] == ']' || class[0] == '^' {
+				class = `\` + class
+			}
+			if _, err := regexp.Compile("[" + negation + class + "]"); err != nil {
+				r.WriteString(regexp.QuoteMeta("["))
+				continue
+			}
+			r.WriteString("[" + negation + class + "]")
+			i = closing
+		default:
+			r.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+	return r.String()
+}
+
+// findGlobClassEnd returns the index of the ] closing the glob character class opened at given index of given pattern
+// or -1 if it is not closed. A ] right after the opening [ or [! belongs to the class, as do POSIX classes like [:alpha:].
+func findGlobClassEnd(pattern string, opening int) int {
+	i := opening + 1
+	if i < len(pattern) && pattern[i] == '!' {
+		i++
+	}
+	if i < len(pattern) && pattern[i] == ']' {
+		i++
+	}
+	for ; i < len(pattern); i++ {
+		if strings.HasPrefix(pattern[i:], "[:") {
+			if end := strings.Index(pattern[i+2:], ":]"); end >= 0 {
+				i += end + 3
+				continue
+			}
+		}
+		if pattern[i] == ']' {
+			return i
+		}
+	}
+	return -1
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// parseNumberWords parses the longest spelled-out number starting from the first word and returns the index of the word
+// following it with its value
+func parseNumberWords(str string, words [][]int, first int) (int, int) {
+	const (
+		none = iota
+		unit
+		tens
+		hundred
+		scale
+	)
+	total, current, previous, lastScale, next := 0, 0, none, 0, first
+	for i := first; i < len(words); i++ {
+		if i > first && !isNumberWordSeparator(str[words[i-1][1]:words[i][0]]) {
+			break
+		}
+		word := strings.ToLower(str[words[i][0]:words[i][1]])
+		if value, ok := numberWordValues[word]; ok {
+			isOnes := value > 0 && value < 10
+			if previous != none && previous != hundred && previous != scale && !(previous == tens && isOnes) {
+				break
+			}
+			current += value
+			previous = unit
+			if value >= 20 {
+				previous = tens
+			}
+		} else if word == "hundred" && (previous == unit || previous == tens) && current < 100 {
+			current *= 100
+			previous = hundred
+		} else if value, ok := numberWordScales[word]; ok && previous != none && previous != scale &&
+			(lastScale == 0 || value < lastScale) {
+			total += current * value
+			current, previous, lastScale = 0, scale, value
+		} else if word == "and" && (previous == hundred || previous == scale) {
+			continue
+		} else {
+			break
+		}
+		next = i + 1
+	}
+	return next, total + current
+}
+
+func isNumberWordSeparator(separator string) bool {
+	return separator == "-" || (separator != "" && strings.TrimSpace(separator) == "")
+}
+
+func matchDomains(str string, isTLD func(string) bool) Match {
+	var indexes [][]int
+	for _, index := range DomainRegexp.FindAllStringIndex(str, -1) {
+		domain := str[index[0]:index[1]]
+		tld := strings.ToLower(domain[strings.LastIndexByte(domain, '.')+1:])
+		isPartOfAddress := index[0] > 0 && strings.IndexByte("@/", str[index[0]-1]) >= 0 ||
+			index[1] < len(str) && str[index[1]] == '@'
+		if len(domain) > 253 || isPartOfAddress || !isTLD(tld) {
+			continue
+		}
+		indexes = append(indexes, index)
+	}
+	return matchFromIndexes(str, indexes)
+}
+
+func containsString(strs []string, str string) bool {
+	for _, candidate := range strs {
+		if candidate == str {
+			return true
+		}
+	}
+	return false
+}
+
+func outermostIndexes(indexes [][]int) [][]int {
+	sort.Slice(indexes, func(i, j int) bool {
+		if indexes[i][0] != indexes[j][0] {
+			return indexes[i][0] < indexes[j][0]
+		}
+		return indexes[i][1] > indexes[j][1]
+	})
+	var outermost [][]int
+	for _, index := range indexes {
+		if len(outermost) > 0 && index[0] < outermost[len(outermost)-1][1] {
+			continue
+		}
+		outermost = append(outermost, index)
+	}
+	return outermost
+}
+
+func matchUnknownWords(str string, dict map[string]struct{}, words [][]int) Match {
+	var indexes [][]int
+	for _, index := range words {
+		if _, ok := dict[strings.ToLower(str[index[0]:index[1]])]; !ok {
+			indexes = append(indexes, index)
+		}
+	}
+	return matchFromIndexes(str, indexes)
+}
+
+func reverseRunes(runes []rune) []rune {
+	reversed := make([]rune, len(runes))
+	for i, r := range runes {
+		reversed[len(runes)-1-i] = r
+	}
+	return reversed
+}
+
+func reverseInts(ints []int) []int {
+	reversed := make([]int, len(ints))
+	for i, n := range ints {
+		reversed[len(ints)-1-i] = n
+	}
+	return reversed
+}
+
+func matchInitials(str string, r *regexp.Regexp) Match {
+	var indexes [][]int
+	for _, index := range r.FindAllStringIndex(str, -1) {
+		before, _ := utf8.DecodeLastRuneInString(str[:index[0]])
+		after, _ := utf8.DecodeRuneInString(str[index[1]:])
+		if before == '.' || isWordRune(before) || isWordRune(after) {
+			continue
+		}
+		indexes = append(indexes, index)
+	}
+	return matchFromIndexes(str, indexes)
+}
+
+// findTableCells returns the trimmed indexes of the cells of the table row between given offsets or nil if the line is
+// not a table row
+func findTableCells(str string, lineStart, lineEnd int) [][]int {
+	line := strings.TrimRightFunc(str[lineStart:lineEnd], unicode.IsSpace)
+	indent := len(line) - len(strings.TrimLeftFunc(line, unicode.IsSpace))
+	if len(line)-indent < 2 || line[indent] != '|' || line[len(line)-1] != '|' {
+		return nil
+	}
+	var cells [][]int
+	cellStart := indent + 1
+	for i := cellStart; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case '|':
+			cell := line[cellStart:i]
+			start := cellStart + len(cell) - len(strings.TrimLeftFunc(cell, unicode.IsSpace))
+			end := cellStart + len(strings.TrimRightFunc(cell, unicode.IsSpace))
+			cells = append(cells, []int{lineStart + start, lineStart + max(start, end)})
+			cellStart = i + 1
+		}
+	}
+	return cells
+}
+
+func isTableSeparator(str string, cells [][]int) bool {
+	for _, cell := range cells {
+		if !TableSeparatorCellRegexp.MatchString(str[cell[0]:cell[1]]) {
+			return false
+		}
+	}
+	return true
+}
+
+var diacriticFolds = newRuneFolds("ÀÁÂÃÄÅÇÈÉÊËÌÍÎÏÑÒÓÔÕÖÙÚÛÜÝàáâãäåçèéêëìíîïñòóôõöùúûüýÿĀāĂăĄąĆćĈĉĊċČčĎďĒēĔĕĖėĘęĚěĜĝĞğĠġĢģĤĥĨĩĪīĬĭĮįİĴĵĶķĹĺĻļĽľŃńŅņŇňŌōŎŏŐőŔŕŖŗŘřŚśŜŝŞşŠšŢţŤťŨũŪūŬŭŮůŰűŲųŴŵŶŷŸŹźŻżŽžØøĐđŁłıĦħŦŧ", "AAAAAACEEEEIIIINOOOOOUUUUYaaaaaaceeeeiiiinooooouuuuyyAaAaAaCcCcCcCcDdEeEeEeEeEeGgGgGgGgHhIiIiIiIiIJjKkLlLlLlNnNnNnOoOoOoRrRrRrSsSsSsSsTtTtUuUuUuUuUuUuWwYyYZzZzZzOoDdLliHhTt")
+
+func newRuneFolds(from, to string) map[rune]rune {
+	folds := make(map[rune]rune)
+	toRunes := []rune(to)
+	for i, r := range []rune(from) {
+		folds[r] = toRunes[i]
+	}
+	return folds
+}
+
+func foldDiacritic(r rune) rune {
+	if folded, ok := diacriticFolds[r]; ok {
+		return folded
+	}
+	return r
+}
+
+// braceAlternatives returns the top level alternatives of given brace group content or the bounds and the optional
+// increment of a range, or nil if the content is neither
+func braceAlternatives(content string) []string {
+	if submatches := BraceRangeRegexp.FindStringSubmatch(content); submatches != nil {
+		bounds := submatches[1:3]
+		if submatches[3] != "" {
+			bounds = append(bounds, submatches[3])
+		}
+		return bounds
+	}
+	var alternatives []string
+	depth, last := 0, 0
+	for i := 0; i < len(content); i++ {
+		switch content[i] {
+		case '\\':
+			i++
+		case '{':
+			depth++
+		case '}':
+			depth--
+		case ',':
+			if depth == 0 {
+				alternatives = append(alternatives, content[last:i])
+				last = i + 1
+			}
+		}
+	}
+	if alternatives == nil {
+		return nil
+	}
+	return append(alternatives, content[last:])
+}
+
+// withinDistance reports whether the Levenshtein distance between a and b is at most maxDist, computing only the band
+// of the distance matrix around its diagonal which can hold such distances. Only the cells bordering the band of a row
+// are reset, so each row takes O(maxDist) time.
+func withinDistance(a, b []rune, maxDist int) bool {
+	if maxDist < 0 || abs(len(a)-len(b)) > maxDist {
+		return false
+	}
+	const unreachable = 1 << 30
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = unreachable
+		if j <= maxDist {
+			previous[j] = j
+		}
+	}
+	for i := 1; i <= len(a); i++ {
+		from, to := max(1, i-maxDist), min(len(b), i+maxDist)
+		current[from-1] = unreachable
+		if from == 1 && i <= maxDist {
+			current[0] = i
+		}
+		if to < len(b) {
+			current[to+1] = unreachable
+		}
+		rowMin := current[from-1]
+		for j := from; j <= to; j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j-1]+cost, min(previous[j], current[j-1])+1)
+			rowMin = min(rowMin, current[j])
+		}
+		if rowMin > maxDist {
+			return false
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)] <= maxDist
+}
+
+func captureCount(c capture) int {
+	if c.source.Counts != nil {
+		return c.source.Counts[c.i]
+	}
+	return 1
+}
+
+func isAdjacentRepeat(str string, c, next capture) bool {
+	return str[c.index[0]:c.index[1]] == str[next.index[0]:next.index[1]] &&
+		strings.TrimSpace(str[c.index[1]:next.index[0]]) == ""
+}
+
+func matchSurroundedGreedy(opening string, closure string) MatcherFuncE {
+	return func(str string) (Match, error) {
+		if opening == "" || closure == "" {
+			return Match{}, errors.New("marker: opening and closure must not be empty")
+		}
+		metaEscapedOpening := regexp.QuoteMeta(opening)
+		metaEscapedClosure := regexp.QuoteMeta(closure)
+		matchPattern := fmt.Sprintf("(?s)%s.*%s", metaEscapedOpening, metaEscapedClosure)
+		r, err := regexp.Compile(matchPattern)
+		if err != nil {
+			return Match{}, err
+		}
+		return MatchRegexp(r)(str), nil
+	}
+}
+
+var confusables = newRuneFolds(
+	"аеорсухіјѕԁԛԝһӏАВЕКМНОРСТХІЈЅԜοαινρτκεΑΒΕΖΗΙΚΜΝΟΡΤΥΧ01|",
+	"aeopcyxijsdqwhlabekmhopctxijswoaivptkeabezhikmnoptyxoll",
+)
+
+func confusableSkeleton(r rune) rune {
+	if folded, ok := confusables[r]; ok {
+		return folded
+	}
+	return unicode.ToLower(r)
+}
+
+func newFoldSet(strs []string) func(string) bool {
+	set := make(map[string]struct{}, len(strs))
+	for _, str := range strs {
+		set[strings.ToLower(str)] = struct{}{}
+	}
+	return func(str string) bool {
+		_, ok := set[strings.ToLower(str)]
+		return ok
+	}
+}
+
+func matchSSN(str string, strict bool) Match {
+	var indexes [][]int
+	for _, submatchIndex := range SSNRegexp.FindAllStringSubmatchIndex(str, -1) {
+		submatches := submatchStrings(str, submatchIndex[2:])
+		area, group, serial := submatches[0]+submatches[3], submatches[1]+submatches[4], submatches[2]+submatches[5]
+		if strict && (area == "000" || area == "666" || area[0] == '9' || group == "00" || serial == "0000") {
+			continue
+		}
+		indexes = append(indexes, submatchIndex[:2])
+	}
+	return matchFromIndexes(str, indexes)
+}
+
+func whitelist(m Match, allowed map[string]struct{}, key func(string) string) Match {
+	str, all := captures(&m)
+	var kept []capture
+	for _, c := range all {
+		if _, ok := allowed[key(str[c.index[0]:c.index[1]])]; ok {
+			kept = append(kept, c)
+		}
+	}
+	return buildMatch(str, kept)
+}
+
+func matchRegexpWithFlags(pattern string, flags string) (MatcherFunc, error) {
+	r, err := regexp.Compile("(?" + flags + ")" + pattern)
+	if err != nil {
+		return nil, err
+	}
+	return MatchRegexp(r), nil
+}
+
+// copyMatch returns a copy of given Match which does not share its slices, so modifying one does not affect the other
+func copyMatch(m Match) Match {
+	copied := Match{Template: m.Template, Offset: m.Offset}
+	if m.Patterns != nil {
+		copied.Patterns = append([]string{}, m.Patterns...)
+	}
+	if m.Groups != nil {
+		copied.Groups = make([][]string, len(m.Groups))
+		for i, groups := range m.Groups {
+			if groups != nil {
+				copied.Groups[i] = append([]string{}, groups...)
+			}
+		}
+	}
+	if m.Depths != nil {
+		copied.Depths = append([]int{}, m.Depths...)
+	}
+	if m.Labels != nil {
+		copied.Labels = append([]string{}, m.Labels...)
+	}
+	if m.Counts != nil {
+		copied.Counts = append([]int{}, m.Counts...)
+	}
+	return copied
+}
+
+// setextTitleStart returns the start of the paragraph whose last line starts at given offset of given string, which is
+// the title of a setext header, or false if the paragraph is not a title because it is a thematic break or belongs to a
+// list item
+func setextTitleStart(str string, lineStart int) (int, bool) {
+	for start := lineStart; ; {
+		lineEnd := start + strings.IndexByte(str[start:], '\n')
+		line := str[start:lineEnd]
+		if ThematicBreakRegexp.MatchString(line) || ListMarkerRegexp.MatchString(line+" ") {
+			return 0, false
+		}
+		if start == 0 {
+			return start, true
+		}
+		previousStart := strings.LastIndexByte(str[:start-1], '\n') + 1
+		previous := str[previousStart : start-1]
+		isBlank := strings.TrimSpace(previous) == ""
+		if isBlank || ATXHeaderRegexp.MatchString(previous) || ThematicBreakRegexp.MatchString(previous) {
+			return start, true
+		}
+		start = previousStart
 	}
-	return s
 }
@@ -1,7 +1,11 @@
 package marker
 
 import (
+	"fmt"
+	"html"
 	"regexp"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -40,6 +44,74 @@ func Test_MatchRegexp(t *testing.T) {
 	assert.Equal(t, expectedMatch, actualMatch)
 }
 
+func Test_MatchRegexpMultiline(t *testing.T) {
+	str := "foo bar\nfoo baz\nbar foo"
+
+	matcher, err := MatchRegexpMultiline(`^foo`)
+	assert.NoError(t, err)
+	assert.Equal(t, Match{Template: "%s bar\n%s baz\nbar foo", Patterns: []string{"foo", "foo"}}, matcher(str))
+	assert.Len(t, MatchRegexp(regexp.MustCompile(`^foo`))(str).Patterns, 1)
+
+	_, err = MatchRegexpMultiline(`^(foo`)
+	assert.Error(t, err)
+
+	_, err = MatchRegexpMultiline(`a)(b`)
+	assert.Error(t, err)
+}
+
+func Test_MatchRegexpDotAll(t *testing.T) {
+	str := "<b>bold\ntext</b> end"
+
+	matcher, err := MatchRegexpDotAll(`<b>.*</b>`)
+	assert.NoError(t, err)
+	assert.Equal(t, Match{Template: "%s end", Patterns: []string{"<b>bold\ntext</b>"}}, matcher(str))
+	assert.Empty(t, MatchRegexp(regexp.MustCompile(`<b>.*</b>`))(str).Patterns)
+
+	_, err = MatchRegexpDotAll(`<b>.*</b>)`)
+	assert.Error(t, err)
+
+	_, err = MatchRegexpDotAll(`a)(b`)
+	assert.Error(t, err)
+}
+
+func Test_MatchRegexps(t *testing.T) {
+	matcher := MatchRegexps(map[string]*regexp.Regexp{
+		"keyword": regexp.MustCompile(`\b(?:if|return)\b`),
+		"int":     regexp.MustCompile(`\d+`),
+		"float":   regexp.MustCompile(`\d+\.\d+`),
+	})
+
+	expectedMatch := Match{
+		Template: "%s x > %s { %s %s%% }",
+		Patterns: []string{"if", "3.14", "return", "42"},
+		Labels:   []string{"keyword", "float", "keyword", "int"},
+	}
+	assert.Equal(t, expectedMatch, matcher("if x > 3.14 { return 42% }"))
+
+	matcher = MatchRegexps(map[string]*regexp.Regexp{
+		"word":  regexp.MustCompile(`\w+`),
+		"ident": regexp.MustCompile(`[a-z]\w*`),
+	})
+	expectedMatch = Match{Template: "%s %s", Patterns: []string{"data", "42"}, Labels: []string{"ident", "word"}}
+	assert.Equal(t, expectedMatch, matcher("data 42"))
+
+	assert.Equal(t, Match{Template: "data"}, MatchRegexps(nil)("data"))
+}
+
+func Test_MatchRegexpTimeout(t *testing.T) {
+	r := regexp.MustCompile(`(\w+\s?)+!`)
+
+	actualMatch, err := MatchRegexpTimeout(r, time.Minute)("Hello world! data")
+	assert.NoError(t, err)
+	assert.Equal(t, Match{Template: "%s data", Patterns: []string{"Hello world!"}}, actualMatch)
+
+	huge := strings.Repeat("Skydome data ", 30000)
+	actualMatch, err = MatchRegexpTimeout(r, time.Nanosecond)(huge)
+	assert.Equal(t, ErrTimeout, err)
+	assert.Equal(t, huge, actualMatch.Render())
+	assert.Empty(t, actualMatch.Patterns)
+}
+
 func Test_MatchTimestamp(t *testing.T) {
 	t.Parallel()
 
@@ -267,6 +339,85 @@ func Test_MatchSurrounded(t *testing.T) {
 	}
 
 	assert.Equal(t, expectedMatch, actualMatch)
+
+	str = "<<outer <<inner>> rest>> <<"
+
+	actualMatch = MatchSurrounded("<<", ">>")(str)
+
+	expectedMatch = Match{
+		Template: "<<outer %s rest>> <<",
+		Patterns: []string{"<<inner>>"},
+	}
+
+	assert.Equal(t, expectedMatch, actualMatch)
+
+	str = "「見出し」と「本文」, 「未完"
+
+	actualMatch = MatchSurrounded("「", "」")(str)
+
+	expectedMatch = Match{
+		Template: "%sと%s, 「未完",
+		Patterns: []string{"「見出し」", "「本文」"},
+	}
+
+	assert.Equal(t, expectedMatch, actualMatch)
+
+	str = "«first\nline» and «ü»"
+
+	actualMatch = MatchSurrounded("«", "»")(str)
+
+	expectedMatch = Match{
+		Template: "%s and %s",
+		Patterns: []string{"«first\nline»", "«ü»"},
+	}
+
+	assert.Equal(t, expectedMatch, actualMatch)
+}
+
+func Test_MatchEmpty(t *testing.T) {
+	str := `call f() with [] and [1], say "" or "a""b", <!----> <!-- note -->`
+
+	assert.Equal(t, []string{"()"}, MatchEmpty("(", ")")(str).Patterns)
+	assert.Equal(t, []string{"[]"}, MatchEmpty("[", "]")(str).Patterns)
+
+	actualMatch := MatchEmpty(`"`, `"`)(str)
+	expectedMatch := Match{
+		Template: `call f() with [] and [1], say %s or "a""b", <!----> <!-- note -->`,
+		Patterns: []string{`""`},
+	}
+	assert.Equal(t, expectedMatch, actualMatch)
+
+	actualMatch = MatchEmpty("<!--", "-->")(str)
+	expectedMatch = Match{
+		Template: `call f() with [] and [1], say "" or "a""b", %s <!-- note -->`,
+		Patterns: []string{"<!---->"},
+	}
+	assert.Equal(t, expectedMatch, actualMatch)
+}
+
+func Test_MatchSurroundedGreedy(t *testing.T) {
+	str := "say 'a' and 'b', then 'c"
+
+	actualMatch := MatchSurrounded("'", "'")(str)
+	expectedMatch := Match{
+		Template: "say %s and %s, then 'c",
+		Patterns: []string{"'a'", "'b'"},
+	}
+	assert.Equal(t, expectedMatch, actualMatch)
+
+	actualMatch = MatchSurroundedGreedy("'", "'")(str)
+	expectedMatch = Match{
+		Template: "say %s, then 'c",
+		Patterns: []string{"'a' and 'b'"},
+	}
+	assert.Equal(t, expectedMatch, actualMatch)
+
+	actualMatch = MatchSurroundedGreedy("(", ")")("f(g(x), h(y)) and (z")
+	expectedMatch = Match{
+		Template: "f%s and (z",
+		Patterns: []string{"(g(x), h(y))"},
+	}
+	assert.Equal(t, expectedMatch, actualMatch)
 }
 
 func Test_MatchBracketSurrounded(t *testing.T) {
@@ -280,6 +431,15 @@ func Test_MatchBracketSurrounded(t *testing.T) {
 	}
 
 	assert.Equal(t, expectedMatch, actualMatch)
+
+	actualMatch = MatchBracketSurrounded()("[a[b] c] and [[d]]")
+
+	expectedMatch = Match{
+		Template: "[a%s c] and [%s]",
+		Patterns: []string{"[b]", "[d]"},
+	}
+
+	assert.Equal(t, expectedMatch, actualMatch)
 }
 
 func Test_MatchParensSurrounded(t *testing.T) {
@@ -293,6 +453,26 @@ func Test_MatchParensSurrounded(t *testing.T) {
 	}
 
 	assert.Equal(t, expectedMatch, actualMatch)
+
+	actualMatch = MatchParensSurrounded()("f(a, g(b)) and (c), (unclosed")
+
+	expectedMatch = Match{
+		Template: "f(a, g%s) and %s, (unclosed",
+		Patterns: []string{"(b)", "(c)"},
+	}
+
+	assert.Equal(t, expectedMatch, actualMatch)
+}
+
+func Test_MatchEmailDomains(t *testing.T) {
+	str := "a@x.com, b@y.org; sure <first.last@mail.example.co> not dev@test"
+	actualMatch := MatchEmailDomains()(str)
+
+	expectedMatch := Match{
+		Template: "a@%s, b@%s; sure <first.last@%s> not dev@test",
+		Patterns: []string{"x.com", "y.org", "mail.example.co"},
+	}
+	assert.Equal(t, expectedMatch, actualMatch)
 }
 
 func Test_MatchDaysOfWeek(t *testing.T) {
@@ -324,6 +504,23 @@ func Test_MatchEmail(t *testing.T) {
 	assert.Equal(t, expectedMatch, actualMatch)
 }
 
+func Test_escapeTemplate(t *testing.T) {
+	assert.Equal(t, "100%% of %%s and %%%%", escapeTemplate("100% of %s and %%"))
+
+	str := "100% of %s is 50%% more"
+	actualMatch := matchFromIndexes(str, [][]int{{0, 4}, {8, 10}})
+	expectedMatch := Match{Template: "%s of %s is 50%%%% more", Patterns: []string{"100%", "%s"}}
+	assert.Equal(t, expectedMatch, actualMatch)
+	assert.Equal(t, str, actualMatch.Render())
+
+	matchers := []MatcherFunc{MatchAll("%"), MatchN("%", 2), MatchMultiple([]string{"100%", "%s"}), MatchAll("")}
+	for _, matcher := range matchers {
+		assert.Equal(t, str, matcher(str).Render())
+		assert.NotContains(t, matcher(str).Render(), "%!")
+	}
+	assert.Equal(t, Match{Template: "%s%s%%", Patterns: []string{"%%", "%%"}}, MatchN("%%", 2)("%%%%%"))
+}
+
 func Test_findPatternMatchIndexes(t *testing.T) {
 
 	t.Parallel()
@@ -395,3 +592,1529 @@ func Test_findPatternMatchIndexes(t *testing.T) {
 	})
 
 }
+
+func Test_MatchNonASCII(t *testing.T) {
+	str := "plain text, smart—é quote and “curly” ones"
+	actualMatch := MatchNonASCII()(str)
+
+	expectedMatch := Match{
+		Template: "plain text, smart%s quote and %scurly%s ones",
+		Patterns: []string{"—é", "“", "”"},
+	}
+
+	assert.Equal(t, expectedMatch, actualMatch)
+
+	actualMatch = MatchNonASCII()("only ascii")
+	assert.Equal(t, Match{Template: "only ascii"}, actualMatch)
+}
+
+func Test_MatchDiffHunkHeaders(t *testing.T) {
+	str := "--- a/marker.go\n+++ b/marker.go\n@@ -1,4 +1,6 @@ package marker\n-old line\n--- removed\n+new line\n@@ -10 +12 @@\n"
+	actualMatch := MatchDiffHunkHeaders()(str)
+
+	expectedMatch := Match{
+		Template: "%s\n%s\n%s package marker\n-old line\n--- removed\n+new line\n%s\n",
+		Patterns: []string{"--- a/marker.go", "+++ b/marker.go", "@@ -1,4 +1,6 @@", "@@ -10 +12 @@"},
+		Groups: [][]string{
+			{"a/marker.go"},
+			{"b/marker.go"},
+			{"1", "4", "1", "6"},
+			{"10", "", "12", ""},
+		},
+	}
+
+	assert.Equal(t, expectedMatch, actualMatch)
+
+	actualMatch = MatchDiffHunkHeaders()("-old line\n+new line")
+	assert.Equal(t, Match{Template: "-old line\n+new line"}, actualMatch)
+}
+
+func Test_MatchNumbersInRange(t *testing.T) {
+	str := "sensor readings: 36.6, 41.2, -5.5, 38 and 39.99"
+	actualMatch := MatchNumbersInRange(-10, 39.5)(str)
+
+	expectedMatch := Match{
+		Template: "sensor readings: %s, 41.2, %s, %s and 39.99",
+		Patterns: []string{"36.6", "-5.5", "38"},
+	}
+
+	assert.Equal(t, expectedMatch, actualMatch)
+
+	str = "overflow 1e400 is skipped"
+	actualMatch = MatchNumbersInRange(0, 100)(str)
+	assert.Equal(t, Match{Template: str}, actualMatch)
+}
+
+func Test_MatchPalindromes(t *testing.T) {
+	str := "Anna saw a level radar at noon, not a rotor-less racecar in Ürü"
+	actualMatch := MatchPalindromes(3)(str)
+
+	expectedMatch := Match{
+		Template: "%s saw a %s %s at %s, not a %s-less %s in %s",
+		Patterns: []string{"Anna", "level", "radar", "noon", "rotor", "racecar", "Ürü"},
+	}
+
+	assert.Equal(t, expectedMatch, actualMatch)
+
+	actualMatch = MatchPalindromes(5)(str)
+	expectedMatch = Match{
+		Template: "Anna saw a %s %s at noon, not a %s-less %s in Ürü",
+		Patterns: []string{"level", "radar", "rotor", "racecar"},
+	}
+
+	assert.Equal(t, expectedMatch, actualMatch)
+}
+
+func Test_MatchTemplatePlaceholders(t *testing.T) {
+	t.Run("printf", func(t *testing.T) {
+		str := "%s sold %d items (%5.2f%% of %[1]s) {{.Ignored}}"
+		actualMatch := MatchTemplatePlaceholders(PrintfPlaceholders)(str)
+
+		expectedMatch := Match{
+			Template: "%s sold %s items (%s%%%% of %s) {{.Ignored}}",
+			Patterns: []string{"%s", "%d", "%5.2f", "%[1]s"},
+		}
+
+		assert.Equal(t, expectedMatch, actualMatch)
+		assert.Equal(t, str, fmt.Sprintf(actualMatch.Template, convertToInterfaceSlice(actualMatch.Patterns)...))
+	})
+
+	t.Run("go", func(t *testing.T) {
+		str := "Hello {{.Name}}, you have {{ len .Items }} items ${ignored}"
+		actualMatch := MatchTemplatePlaceholders(GoTemplatePlaceholders)(str)
+
+		expectedMatch := Match{
+			Template: "Hello %s, you have %s items ${ignored}",
+			Patterns: []string{"{{.Name}}", "{{ len .Items }}"},
+		}
+
+		assert.Equal(t, expectedMatch, actualMatch)
+	})
+
+	t.Run("shell", func(t *testing.T) {
+		str := "cp ${SRC_DIR}/file ${dest} at $HOME"
+		actualMatch := MatchTemplatePlaceholders(ShellPlaceholders)(str)
+
+		expectedMatch := Match{
+			Template: "cp %s/file %s at $HOME",
+			Patterns: []string{"${SRC_DIR}", "${dest}"},
+		}
+
+		assert.Equal(t, expectedMatch, actualMatch)
+	})
+
+	t.Run("Unknown style", func(t *testing.T) {
+		actualMatch := MatchTemplatePlaceholders("unknown")("%s {{.}} ${x}")
+		assert.Equal(t, Match{Template: "%%s {{.}} ${x}"}, actualMatch)
+	})
+}
+
+func Test_MatchAroundIndex(t *testing.T) {
+	str := "alpha beta  gamma"
+
+	t.Run("Inside", func(t *testing.T) {
+		actualMatch := MatchAroundIndex(MatchRegexp(regexp.MustCompile(`\w+`)), 8)(str)
+		expectedMatch := Match{Template: "alpha %s  gamma", Patterns: []string{"beta"}}
+		assert.Equal(t, expectedMatch, actualMatch)
+	})
+
+	t.Run("Between", func(t *testing.T) {
+		actualMatch := MatchAroundIndex(MatchRegexp(regexp.MustCompile(`\w+`)), 11)(str)
+		expectedMatch := Match{Template: "alpha %s  gamma", Patterns: []string{"beta"}}
+		assert.Equal(t, expectedMatch, actualMatch)
+
+		actualMatch = MatchAroundIndex(MatchRegexp(regexp.MustCompile(`ab`)), 4)("ab___ab")
+		expectedMatch = Match{Template: "ab___%s", Patterns: []string{"ab"}}
+		assert.Equal(t, expectedMatch, actualMatch)
+	})
+
+	t.Run("Boundary", func(t *testing.T) {
+		actualMatch := MatchAroundIndex(MatchRegexp(regexp.MustCompile(`[a-z]+|[0-9]+`)), 3)("abc123")
+		expectedMatch := Match{Template: "%s123", Patterns: []string{"abc"}}
+		assert.Equal(t, expectedMatch, actualMatch)
+	})
+
+	t.Run("No patterns", func(t *testing.T) {
+		actualMatch := MatchAroundIndex(MatchAll("delta"), 3)(str)
+		assert.Equal(t, Match{Template: str}, actualMatch)
+	})
+}
+
+func Test_MatchBracketsWithDepth(t *testing.T) {
+	str := "f(a[b{c}d]e) (x]"
+	actualMatch := MatchBracketsWithDepth()(str)
+
+	expectedMatch := Match{
+		Template: "f%sa%sb%sc%sd%se%s (x]",
+		Patterns: []string{"(", "[", "{", "}", "]", ")"},
+		Depths:   []int{0, 1, 2, 2, 1, 0},
+	}
+
+	assert.Equal(t, expectedMatch, actualMatch)
+
+	actualMatch = MatchBracketsWithDepth()("[a[b]c] ]")
+	expectedMatch = Match{
+		Template: "%sa%sb%sc%s ]",
+		Patterns: []string{"[", "[", "]", "]"},
+		Depths:   []int{0, 1, 1, 0},
+	}
+
+	assert.Equal(t, expectedMatch, actualMatch)
+
+	actualMatch = MatchBracketsWithDepth()("( [x] and ([)] {a(b)}")
+	expectedMatch = Match{
+		Template: "( %sx%s and (%s)%s %sa%sb%s%s",
+		Patterns: []string{"[", "]", "[", "]", "{", "(", ")", "}"},
+		Depths:   []int{0, 0, 0, 0, 0, 1, 1, 0},
+	}
+
+	assert.Equal(t, expectedMatch, actualMatch)
+}
+
+func Test_MatchShortcodes(t *testing.T) {
+	str := "say [b][i]text[/i][/b] or [url=https://x.com]link[/url] but [u]unclosed and [i]closed[/i]"
+	actualMatch := MatchShortcodes()(str)
+
+	expectedMatch := Match{
+		Template: "say %s or %s but [u]unclosed and %s",
+		Patterns: []string{"[b][i]text[/i][/b]", "[url=https://x.com]link[/url]", "[i]closed[/i]"},
+		Groups: [][]string{
+			{"b", ""},
+			{"url", "https://x.com"},
+			{"i", ""},
+		},
+	}
+
+	assert.Equal(t, expectedMatch, actualMatch)
+}
+
+func Test_MatchCamelCase(t *testing.T) {
+	str := "call getHTTPResponseCode on HTTPServer with userID2, utf8String and ALLCAPS or plain snake_caseName"
+	actualMatch := MatchCamelCase()(str)
+
+	expectedMatch := Match{
+		Template: "call %s on %s with %s, %s and ALLCAPS or plain snake_caseName",
+		Patterns: []string{"getHTTPResponseCode", "HTTPServer", "userID2", "utf8String"},
+		Groups: [][]string{
+			{"get", "HTTP", "Response", "Code"},
+			{"HTTP", "Server"},
+			{"user", "ID2"},
+			{"utf8", "String"},
+		},
+	}
+
+	assert.Equal(t, expectedMatch, actualMatch)
+}
+
+func Test_MatchDuration(t *testing.T) {
+	t.Run("Go", func(t *testing.T) {
+		str := "took 1h30m and 250ms, then 2.5s over 30 attempts in 3 hours"
+		actualMatch := MatchGoDuration()(str)
+
+		expectedMatch := Match{
+			Template: "took %s and %s, then %s over 30 attempts in 3 hours",
+			Patterns: []string{"1h30m", "250ms", "2.5s"},
+		}
+
+		assert.Equal(t, expectedMatch, actualMatch)
+	})
+
+	t.Run("Human", func(t *testing.T) {
+		str := "took 1h30m and 250ms, then 2.5s over 30 attempts in 3 hours or 45 Minutes"
+		actualMatch := MatchDuration()(str)
+
+		expectedMatch := Match{
+			Template: "took %s and %s, then %s over 30 attempts in %s or %s",
+			Patterns: []string{"1h30m", "250ms", "2.5s", "3 hours", "45 Minutes"},
+		}
+
+		assert.Equal(t, expectedMatch, actualMatch)
+	})
+}
+
+func Test_MatchListMarkers(t *testing.T) {
+	str := "Steps:\n1. first costs 1.5 units\n2) second\n  - nested\n  * another\n3.14 is not an item\n-not an item"
+
+	actualMatch := MatchListMarkers()(str)
+	expectedMatch := Match{
+		Template: "Steps:\n%s first costs 1.5 units\n%s second\n  %s nested\n  %s another\n3.14 is not an item\n-not an item",
+		Patterns: []string{"1.", "2)", "-", "*"},
+	}
+	assert.Equal(t, expectedMatch, actualMatch)
+
+	actualMatch = MatchIndentedListMarkers()(str)
+	expectedMatch = Match{
+		Template: "Steps:\n%s first costs 1.5 units\n%s second\n%s nested\n%s another\n3.14 is not an item\n-not an item",
+		Patterns: []string{"1.", "2)", "  -", "  *"},
+	}
+	assert.Equal(t, expectedMatch, actualMatch)
+}
+
+func Test_MatchAny(t *testing.T) {
+	str := "Contact me@skydome.io on Monday"
+	actualMatch := MatchAny(MatchAll("me"), MatchEmail(), MatchDaysOfWeek())(str)
+
+	expectedMatch := Match{
+		Template: "Contact %s@skydo%s.io on %s",
+		Patterns: []string{"me", "me", "Monday"},
+	}
+
+	assert.Equal(t, expectedMatch, actualMatch)
+
+	actualMatch = MatchAny(MatchEmail(), MatchAll("me"))(str)
+	expectedMatch = Match{
+		Template: "Contact %s on Monday",
+		Patterns: []string{"me@skydome.io"},
+	}
+
+	assert.Equal(t, expectedMatch, actualMatch)
+}
+
+func Test_Limit(t *testing.T) {
+	str := "Skydome is Skydome is Skydome"
+	assert.Equal(t, MatchN("Skydome", 2)(str), Limit(MatchAll("Skydome"), 2)(str))
+	assert.Equal(t, Match{Template: str}, Limit(MatchAll("Skydome"), 0)(str))
+}
+
+func Test_Cached(t *testing.T) {
+	calls := 0
+	matcher := Cached(func(str string) Match {
+		calls++
+		return MatchAll("Skydome")(str)
+	})
+	str := "Skydome is Skydome"
+
+	assert.Equal(t, MatchAll("Skydome")(str), matcher(str))
+	actualMatch := matcher(str)
+	assert.Equal(t, MatchAll("Skydome")(str), actualMatch)
+	assert.Equal(t, 1, calls)
+
+	actualMatch.Patterns[0] = "modified"
+	assert.Equal(t, MatchAll("Skydome")(str), matcher(str))
+	assert.Equal(t, 1, calls)
+
+	assert.Equal(t, MatchAll("Skydome")("Skydome data"), matcher("Skydome data"))
+	assert.Equal(t, 2, calls)
+	matcher(str)
+	assert.Equal(t, 3, calls)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.Equal(t, MatchAll("Skydome")(str), matcher(str))
+		}()
+	}
+	wg.Wait()
+}
+
+func Test_CaseInsensitive(t *testing.T) {
+	str := "SKYDOME is Skydome, İstanbul is skydome"
+	actualMatch := CaseInsensitive(MatchAll("skydome"))(str)
+
+	expectedMatch := Match{
+		Template: "%s is %s, İstanbul is %s",
+		Patterns: []string{"SKYDOME", "Skydome", "skydome"},
+	}
+
+	assert.Equal(t, expectedMatch, actualMatch)
+}
+
+func Test_MatchBytes(t *testing.T) {
+	isControl := func(b byte) bool { return b < 0x20 || b == 0x7f }
+
+	str := "header\x00\x01\x02body\ttail\x7f"
+	actualMatch := MatchBytes(isControl)(str)
+
+	expectedMatch := Match{
+		Template: "header%sbody%stail%s",
+		Patterns: []string{"\x00\x01\x02", "\t", "\x7f"},
+	}
+
+	assert.Equal(t, expectedMatch, actualMatch)
+
+	actualMatch = MatchBytes(func(b byte) bool { return b == 0xa9 })("©")
+	expectedMatch = Match{Template: "\xc2%s", Patterns: []string{"\xa9"}}
+
+	assert.Equal(t, expectedMatch, actualMatch)
+}
+
+func Test_MatchGitSHA(t *testing.T) {
+	sha256 := "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+	str := "a882646 merged 5d1f3c2e8b7a6f4d3c2b1a0e9f8d7c6b5a4e3d2c, skipped 3d1f3c, defaced and " + sha256
+
+	actualMatch := MatchGitSHA()(str)
+	expectedMatch := Match{
+		Template: "%s merged %s, skipped 3d1f3c, defaced and " + sha256,
+		Patterns: []string{"a882646", "5d1f3c2e8b7a6f4d3c2b1a0e9f8d7c6b5a4e3d2c"},
+	}
+	assert.Equal(t, expectedMatch, actualMatch)
+
+	actualMatch = MatchGitSHALength(7, 64)(str)
+	expectedMatch = Match{
+		Template: "%s merged %s, skipped 3d1f3c, defaced and %s",
+		Patterns: []string{"a882646", "5d1f3c2e8b7a6f4d3c2b1a0e9f8d7c6b5a4e3d2c", sha256},
+	}
+	assert.Equal(t, expectedMatch, actualMatch)
+
+	assert.Equal(t, []string{sha256}, MatchGitSHALength(41, 2000)(str).Patterns)
+	assert.NotPanics(t, func() {
+		assert.Empty(t, MatchGitSHALength(40, 7)(str).Patterns)
+	})
+}
+
+func Test_MatchGitRef(t *testing.T) {
+	str := "pushed refs/heads/feature/v1.2 and refs/tags/v1.0."
+	actualMatch := MatchGitRef()(str)
+
+	expectedMatch := Match{
+		Template: "pushed %s and %s.",
+		Patterns: []string{"refs/heads/feature/v1.2", "refs/tags/v1.0"},
+	}
+
+	assert.Equal(t, expectedMatch, actualMatch)
+}
+
+func Test_MatchInRange(t *testing.T) {
+	str := "data data data data"
+
+	actualMatch := MatchInRange(MatchAll("data"), 3, 15)(str)
+	expectedMatch := Match{Template: "data %s %s data", Patterns: []string{"data", "data"}}
+	assert.Equal(t, expectedMatch, actualMatch)
+
+	actualMatch = MatchInRange(MatchAll("data"), 5, 9)(str)
+	expectedMatch = Match{Template: "data %s data data", Patterns: []string{"data"}}
+	assert.Equal(t, expectedMatch, actualMatch)
+
+	actualMatch = MatchInRange(MatchAll("data"), 7, 7)(str)
+	assert.Equal(t, Match{Template: str}, actualMatch)
+}
+
+func Test_MatchCSVFields(t *testing.T) {
+	str := `a,"b,c","d""e",f`
+
+	actualMatch := MatchCSVFields()(str)
+	expectedMatch := Match{
+		Template: `%s,"%s","%s",%s`,
+		Patterns: []string{"a", "b,c", `d""e`, "f"},
+		Groups:   [][]string{{"a"}, {"b,c"}, {`d"e`}, {"f"}},
+	}
+	assert.Equal(t, expectedMatch, actualMatch)
+
+	actualMatch = MatchQuotedCSVFields()(str)
+	expectedMatch = Match{
+		Template: `%s,%s,%s,%s`,
+		Patterns: []string{"a", `"b,c"`, `"d""e"`, "f"},
+		Groups:   [][]string{{"a"}, {"b,c"}, {`d"e`}, {"f"}},
+	}
+	assert.Equal(t, expectedMatch, actualMatch)
+
+	str = "x,,\"multi\nline\"\r\ny,z\n"
+	actualMatch = MatchCSVFields()(str)
+	expectedMatch = Match{
+		Template: "%s,%s,\"%s\"\r\n%s,%s\n",
+		Patterns: []string{"x", "", "multi\nline", "y", "z"},
+		Groups:   [][]string{{"x"}, {""}, {"multi\nline"}, {"y"}, {"z"}},
+	}
+	assert.Equal(t, expectedMatch, actualMatch)
+}
+
+func Test_MatchPrefix(t *testing.T) {
+	actualMatch := MatchPrefix("[INFO]")("[INFO] started [INFO]")
+	assert.Equal(t, Match{Template: "%s started [INFO]", Patterns: []string{"[INFO]"}}, actualMatch)
+
+	actualMatch = MatchPrefix("[INFO]")("started [INFO]")
+	assert.Equal(t, Match{Template: "started [INFO]"}, actualMatch)
+
+	actualMatch = MatchPrefix("[INFO] started")("[INFO]")
+	assert.Equal(t, Match{Template: "[INFO]"}, actualMatch)
+
+	actualMatch = MatchPrefix("[INFO]")("[INFO]")
+	assert.Equal(t, Match{Template: "%s", Patterns: []string{"[INFO]"}}, actualMatch)
+}
+
+func Test_MatchSuffix(t *testing.T) {
+	actualMatch := MatchSuffix(".go")("main.go.go")
+	assert.Equal(t, Match{Template: "main.go%s", Patterns: []string{".go"}}, actualMatch)
+
+	actualMatch = MatchSuffix(".go")("main.go.txt")
+	assert.Equal(t, Match{Template: "main.go.txt"}, actualMatch)
+
+	actualMatch = MatchSuffix("main.go")(".go")
+	assert.Equal(t, Match{Template: ".go"}, actualMatch)
+
+	actualMatch = MatchSuffix("main.go")("main.go")
+	assert.Equal(t, Match{Template: "%s", Patterns: []string{"main.go"}}, actualMatch)
+}
+
+func Test_MatchInterleave(t *testing.T) {
+	str := "row, row, row, row, row"
+	actualMatch := MatchInterleave(MatchAll("row"), MatchAll("row"))(str)
+
+	expectedMatch := Match{
+		Template: "%s, %s, %s, %s, %s",
+		Patterns: []string{"row", "row", "row", "row", "row"},
+		Labels:   []string{OddLabel, EvenLabel, OddLabel, EvenLabel, OddLabel},
+	}
+
+	assert.Equal(t, expectedMatch, actualMatch)
+
+	str = "a1 b2 a3 a4 b5"
+	actualMatch = MatchInterleave(MatchRegexp(regexp.MustCompile(`a\d`)), MatchRegexp(regexp.MustCompile(`b\d`)))(str)
+
+	expectedMatch = Match{
+		Template: "%s %s %s a4 %s",
+		Patterns: []string{"a1", "b2", "a3", "b5"},
+		Labels:   []string{OddLabel, EvenLabel, OddLabel, EvenLabel},
+	}
+
+	assert.Equal(t, expectedMatch, actualMatch)
+}
+
+func Test_MatchSurroundedE(t *testing.T) {
+	actualMatch, err := MatchSurroundedE("-", "-")("a -debug- message")
+	assert.NoError(t, err)
+	assert.Equal(t, Match{Template: "a %s message", Patterns: []string{"-debug-"}}, actualMatch)
+
+	actualMatch, err = MatchSurroundedE("z-a", "]")("z-a text]")
+	assert.NoError(t, err)
+	assert.Equal(t, Match{Template: "%s", Patterns: []string{"z-a text]"}}, actualMatch)
+
+	_, err = MatchSurroundedE("", "]")("text]")
+	assert.Error(t, err)
+
+	assert.Panics(t, func() { MatchSurrounded("[", "")("[text") })
+}
+
+func Test_Safe(t *testing.T) {
+	actualMatch, err := Safe(MatchSurrounded("[", "]"))("[INFO] message")
+	assert.NoError(t, err)
+	assert.Equal(t, Match{Template: "%s message", Patterns: []string{"[INFO]"}}, actualMatch)
+
+	_, err = Safe(MatchSurrounded("", "]"))("text]")
+	assert.Error(t, err)
+}
+
+func Test_MatchAnagramsOf(t *testing.T) {
+	str := "Listen, silent tinsel: enlist the lists, not silents."
+
+	actualMatch := MatchAnagramsOf("silent")(str)
+	expectedMatch := Match{
+		Template: "%s, %s %s: %s the lists, not silents.",
+		Patterns: []string{"Listen", "silent", "tinsel", "enlist"},
+	}
+	assert.Equal(t, expectedMatch, actualMatch)
+
+	actualMatch = MatchStrictAnagramsOf("Silent")(str)
+	expectedMatch = Match{
+		Template: "%s, silent %s: %s the lists, not silents.",
+		Patterns: []string{"Listen", "tinsel", "enlist"},
+	}
+	assert.Equal(t, expectedMatch, actualMatch)
+}
+
+func Test_MatchFractions(t *testing.T) {
+	str := "add 1/2 cup and ¾ tsp, bake 22/7 hours on 12/25/2023 or 12/25"
+
+	actualMatch := MatchFractions()(str)
+	expectedMatch := Match{
+		Template: "add %s cup and %s tsp, bake %s hours on 12/25/2023 or 12/25",
+		Patterns: []string{"1/2", "¾", "22/7"},
+	}
+	assert.Equal(t, expectedMatch, actualMatch)
+
+	actualMatch = MatchFractionsWithMaxDenominator(100)(str)
+	expectedMatch = Match{
+		Template: "add %s cup and %s tsp, bake %s hours on 12/25/2023 or %s",
+		Patterns: []string{"1/2", "¾", "22/7", "12/25"},
+	}
+	assert.Equal(t, expectedMatch, actualMatch)
+}
+
+func Test_MaxPatternLength(t *testing.T) {
+	huge := strings.Repeat("unbalanced ", 1000)
+	str := "[short] text [" + huge + "]"
+
+	actualMatch := MaxPatternLength(MatchBracketSurrounded(), 100)(str)
+	expectedMatch := Match{
+		Template: "%s text [" + huge + "]",
+		Patterns: []string{"[short]"},
+	}
+
+	assert.Equal(t, expectedMatch, actualMatch)
+	assert.Len(t, MatchBracketSurrounded()(str).Patterns, 2)
+}
+
+func Test_Whitelist(t *testing.T) {
+	str := "Skydome ships data, skydome DATA"
+	words := MatchRegexp(WordRegexp)
+	allowed := map[string]struct{}{"Skydome": {}, "data": {}}
+
+	expectedMatch := Match{Template: "%s ships %s, skydome DATA", Patterns: []string{"Skydome", "data"}}
+	assert.Equal(t, expectedMatch, Whitelist(words, allowed)(str))
+
+	expectedMatch = Match{
+		Template: "%s ships %s, %s %s",
+		Patterns: []string{"Skydome", "data", "skydome", "DATA"},
+	}
+	assert.Equal(t, expectedMatch, WhitelistFold(words, allowed)(str))
+
+	assert.Equal(t, Match{Template: "Skydome ships data, skydome DATA"}, Whitelist(words, nil)(str))
+}
+
+func Test_MatchPrecededBy(t *testing.T) {
+	str := "pay $15 for 2 items, $3.50 each or all of $0"
+
+	actualMatch := MatchPrecededBy("$", MatchRegexp(NumberRegexp))(str)
+	expectedMatch := Match{
+		Template: "pay $%s for 2 items, $%s each or all of $%s",
+		Patterns: []string{"15", "3.50", "0"},
+	}
+	assert.Equal(t, expectedMatch, actualMatch)
+
+	actualMatch = MatchPrecededBy("€", MatchRegexp(NumberRegexp))(str)
+	assert.Equal(t, Match{Template: "pay $15 for 2 items, $3.50 each or all of $0"}, actualMatch)
+}
+
+func Test_MatchFollowedBy(t *testing.T) {
+	str := "grew 15% in 2023, then 3.5% and 12 points, $40%"
+
+	actualMatch := MatchFollowedBy(MatchRegexp(NumberRegexp), "%")(str)
+	expectedMatch := Match{
+		Template: "grew %s%% in 2023, then %s%% and 12 points, $%s%%",
+		Patterns: []string{"15", "3.5", "40"},
+	}
+	assert.Equal(t, expectedMatch, actualMatch)
+
+	actualMatch = MatchFollowedBy(MatchPrecededBy("$", MatchRegexp(NumberRegexp)), "%")(str)
+	expectedMatch = Match{
+		Template: "grew 15%% in 2023, then 3.5%% and 12 points, $%s%%",
+		Patterns: []string{"40"},
+	}
+	assert.Equal(t, expectedMatch, actualMatch)
+}
+
+func Test_MinGap(t *testing.T) {
+	str := "x____x______x__x"
+
+	actualMatch := MinGap(MatchAll("x"), 10)(str)
+	expectedMatch := Match{
+		Template: "%s____x______%s__x",
+		Patterns: []string{"x", "x"},
+	}
+	assert.Equal(t, expectedMatch, actualMatch)
+	assert.Equal(t, [][]int{{0, 1}, {12, 13}}, actualMatch.Indexes())
+
+	assert.Equal(t, MatchAll("x")(str), MinGap(MatchAll("x"), 0)(str))
+}
+
+func Test_MatchSymbols(t *testing.T) {
+	str := "Marker™ ©2019 Skydome®© see §4¶2"
+
+	actualMatch := MatchLegalSymbols()(str)
+	expectedMatch := Match{
+		Template: "Marker%s %s2019 Skydome%s%s see %s4%s2",
+		Patterns: []string{"™", "©", "®", "©", "§", "¶"},
+	}
+	assert.Equal(t, expectedMatch, actualMatch)
+
+	actualMatch = MatchSymbols([]rune{'©', '™'})(str)
+	expectedMatch = Match{
+		Template: "Marker%s %s2019 Skydome®%s see §4¶2",
+		Patterns: []string{"™", "©", "©"},
+	}
+	assert.Equal(t, expectedMatch, actualMatch)
+}
+
+func Test_MatchInvisibles(t *testing.T) {
+	str := "\uFEFFname,fully\u00A0sure zero\u200Bwidth"
+	actualMatch := MatchInvisibles()(str)
+
+	expectedMatch := Match{
+		Template: "%sname,fully%ssure zero%swidth",
+		Patterns: []string{"\uFEFF", "\u00A0", "\u200B"},
+	}
+	assert.Equal(t, expectedMatch, actualMatch)
+	assert.Equal(t, "name,fullysure zerowidth", actualMatch.Map(func(string) string { return "" }).Render())
+}
+
+func Test_MatchRune(t *testing.T) {
+	str := "€5, half of €20 or 7€"
+
+	actualMatch := MatchRune('€')(str)
+	expectedMatch := Match{
+		Template: "%s5, half of %s20 or 7%s",
+		Patterns: []string{"€", "€", "€"},
+	}
+	assert.Equal(t, expectedMatch, actualMatch)
+	assert.Equal(t, [][]int{{0, 3}, {14, 17}, {24, 27}}, actualMatch.Indexes())
+
+	actualMatch = MatchRuneN('€', 2)(str)
+	expectedMatch = Match{
+		Template: "%s5, half of %s20 or 7€",
+		Patterns: []string{"€", "€"},
+	}
+	assert.Equal(t, expectedMatch, actualMatch)
+}
+
+func Test_CollapseAdjacent(t *testing.T) {
+	str := "no no  no, no\nno way; way way"
+	actualMatch := CollapseAdjacent(MatchRegexp(WordRegexp))(str)
+
+	expectedMatch := Match{
+		Template: "%s, %s %s; %s",
+		Patterns: []string{"no", "no", "way", "way"},
+		Counts:   []int{3, 2, 1, 2},
+	}
+	assert.Equal(t, expectedMatch, actualMatch)
+
+	repeated := actualMatch.Patterns
+	for i, count := range actualMatch.Counts {
+		if count > 1 {
+			repeated[i] = fmt.Sprintf("%s (%d×)", repeated[i], count)
+		}
+	}
+	assert.Equal(t, "no (3×), no (2×) way; way (2×)", actualMatch.Render())
+
+	assert.Equal(t, Match{Template: "no data"}, CollapseAdjacent(MatchAll("skydome"))("no data"))
+}
+
+func Test_MatchPriority(t *testing.T) {
+	str := "Skydome is a data company, fully data driven"
+	actualMatch := MatchPriority(MatchAll("data company"), MatchAll("data"), MatchMultiple([]string{"company", "Skydome"}))(str)
+
+	expectedMatch := Match{
+		Template: "%s is a %s, fully %s driven",
+		Patterns: []string{"Skydome", "data company", "data"},
+		Labels:   []string{"2", "0", "1"},
+	}
+
+	assert.Equal(t, expectedMatch, actualMatch)
+
+	actualMatch = MatchPriority(MatchAll("data"), MatchRegexp(regexp.MustCompile(`^\w+|\w+$`)))(str)
+	expectedMatch = Match{
+		Template: "%s is a %s company, fully %s %s",
+		Patterns: []string{"Skydome", "data", "data", "driven"},
+		Labels:   []string{"1", "0", "0", "1"},
+	}
+
+	assert.Equal(t, expectedMatch, actualMatch)
+}
+
+func Test_MatchSequence(t *testing.T) {
+	str := "Skydome is a data company"
+	actualMatch := MatchSequence(MatchAll("data company"), MatchAll("data"), MatchAll("Skydome"))(str)
+
+	expectedMatch := Match{
+		Template: "%s is a %s",
+		Patterns: []string{"Skydome", "data company"},
+	}
+
+	assert.Equal(t, expectedMatch, actualMatch)
+}
+
+func Test_MatchRedactions(t *testing.T) {
+	str := "mail [REDACTED], ****@skydome.io or XXXX but not **bold** and me@skydome.io"
+
+	actualMatch := MatchRedactions()(str)
+	expectedMatch := Match{
+		Template: "mail %s, %s@skydome.io or %s but not **bold** and me@skydome.io",
+		Patterns: []string{"[REDACTED]", "****", "XXXX"},
+	}
+	assert.Equal(t, expectedMatch, actualMatch)
+
+	redactedEmailRegexp := regexp.MustCompile(`[a-z0-9*]+@[a-z0-9.]+`)
+	actualMatch = MatchSequence(MatchRedactions(), MatchRegexp(redactedEmailRegexp))(str)
+	expectedMatch = Match{
+		Template: "mail %s, %s@skydome.io or %s but not **bold** and %s",
+		Patterns: []string{"[REDACTED]", "****", "XXXX", "me@skydome.io"},
+	}
+	assert.Equal(t, expectedMatch, actualMatch)
+
+	actualMatch = MatchRedactions('#')("card ######1234 XXXX")
+	expectedMatch = Match{Template: "card %s1234 XXXX", Patterns: []string{"######"}}
+	assert.Equal(t, expectedMatch, actualMatch)
+}
+
+func Test_MatchGlob(t *testing.T) {
+	str := "edit main.go and (marker_test.go), not main.gopher"
+	actualMatch := MatchGlob("*.go")(str)
+	expectedMatch := Match{
+		Template: "edit %s and (%s), not main.gopher",
+		Patterns: []string{"main.go", "marker_test.go"},
+	}
+	assert.Equal(t, expectedMatch, actualMatch)
+
+	str = "cat, bat and scat sat on a mat"
+	actualMatch = MatchGlob("?at")(str)
+	expectedMatch = Match{
+		Template: "%s, %s and scat %s on a %s",
+		Patterns: []string{"cat", "bat", "sat", "mat"},
+	}
+	assert.Equal(t, expectedMatch, actualMatch)
+
+	actualMatch = MatchGlob("[bc]at")(str)
+	expectedMatch = Match{
+		Template: "%s, %s and scat sat on a mat",
+		Patterns: []string{"cat", "bat"},
+	}
+	assert.Equal(t, expectedMatch, actualMatch)
+
+	actualMatch = MatchGlob("[!bcs]at")(str)
+	expectedMatch = Match{
+		Template: "cat, bat and scat sat on a %s",
+		Patterns: []string{"mat"},
+	}
+	assert.Equal(t, expectedMatch, actualMatch)
+
+	actualMatch = MatchGlobSubstring("?at")("scat")
+	expectedMatch = Match{Template: "s%s", Patterns: []string{"cat"}}
+	assert.Equal(t, expectedMatch, actualMatch)
+
+	assert.Equal(t, []string{"cat", "bat", "sat", "mat"}, MatchGlob("[[:alpha:]]at")(str).Patterns)
+	assert.Equal(t, []string{"]at"}, MatchGlob("[]]at")("]at, cat").Patterns)
+	assert.Equal(t, []string{"[z-a]at"}, MatchGlobSubstring("[z-a]at")("cat [z-a]at").Patterns)
+	assert.Equal(t, []string{"[!]at"}, MatchGlobSubstring("[!]at")("cat [!]at").Patterns)
+	assert.Equal(t, []string{"[ca"}, MatchGlobSubstring("[c?")("cat [ca").Patterns)
+}
+
+func Test_MatchNumberWords(t *testing.T) {
+	str := "I have twenty-three apples and one hundred oranges"
+	actualMatch := MatchNumberWords()(str)
+	expectedMatch := Match{
+		Template: "I have %s apples and %s oranges",
+		Patterns: []string{"twenty-three", "one hundred"},
+		Groups:   [][]string{{"23"}, {"100"}},
+	}
+	assert.Equal(t, expectedMatch, actualMatch)
+
+	str = "One thousand two hundred and five, one hundred and five and six, two million thousand, zero"
+	actualMatch = MatchNumberWords()(str)
+	expectedMatch = Match{
+		Template: "%s, %s and %s, %s thousand, %s",
+		Patterns: []string{"One thousand two hundred and five", "one hundred and five", "six", "two million", "zero"},
+		Groups:   [][]string{{"1205"}, {"105"}, {"6"}, {"2000000"}, {"0"}},
+	}
+	assert.Equal(t, expectedMatch, actualMatch)
+}
+
+func Test_MatchShouting(t *testing.T) {
+	str := "STOP DOING THAT please"
+	actualMatch := MatchShouting(2)(str)
+	expectedMatch := Match{Template: "%s please", Patterns: []string{"STOP DOING THAT"}}
+	assert.Equal(t, expectedMatch, actualMatch)
+
+	str = "I SAID NO. NOT NOW, OK? FINE I GUESS"
+	actualMatch = MatchShouting(2)(str)
+	expectedMatch = Match{Template: "I %s. %s? %s", Patterns: []string{"SAID NO", "NOT NOW, OK", "FINE I GUESS"}}
+	assert.Equal(t, expectedMatch, actualMatch)
+
+	actualMatch = MatchShoutingWithMinWordLength(2, 3)(str)
+	expectedMatch = Match{Template: "I SAID NO. %s, OK? %s", Patterns: []string{"NOT NOW", "FINE I GUESS"}}
+	assert.Equal(t, expectedMatch, actualMatch)
+}
+
+func Test_MatchDomains(t *testing.T) {
+	str := "visit example.com or sub.example.co.uk for report.pdf, not me@skydome.io or https://skydome.io"
+
+	actualMatch := MatchDomains()(str)
+	expectedMatch := Match{
+		Template: "visit %s or %s for report.pdf, not me@skydome.io or https://skydome.io",
+		Patterns: []string{"example.com", "sub.example.co.uk"},
+	}
+	assert.Equal(t, expectedMatch, actualMatch)
+
+	actualMatch = MatchDomainsWithTLDs([]string{"com", "pdf"})(str)
+	expectedMatch = Match{
+		Template: "visit %s or sub.example.co.uk for %s, not me@skydome.io or https://skydome.io",
+		Patterns: []string{"example.com", "report.pdf"},
+	}
+	assert.Equal(t, expectedMatch, actualMatch)
+
+	actualMatch = MatchDomainsWithTLDs([]string{"com", "doe"})("mail john.doe@example.com at example.com")
+	expectedMatch = Match{Template: "mail john.doe@example.com at %s", Patterns: []string{"example.com"}}
+	assert.Equal(t, expectedMatch, actualMatch)
+	assert.Empty(t, MatchDomains()("mail john.doe@example.com").Patterns)
+}
+
+func Test_MatchHTMLEntities(t *testing.T) {
+	str := "Tom &amp; Jerry&nbsp;&#169; 2019 &#x1F600; &bogus; & &#; &amp"
+	actualMatch := MatchHTMLEntities()(str)
+
+	expectedMatch := Match{
+		Template: "Tom %s Jerry%s%s 2019 %s &bogus; & &#; &amp",
+		Patterns: []string{"&amp;", "&nbsp;", "&#169;", "&#x1F600;"},
+	}
+
+	assert.Equal(t, expectedMatch, actualMatch)
+	assert.Equal(t, "Tom & Jerry © 2019 😀 &bogus; & &#; &amp", actualMatch.Map(html.UnescapeString).Render())
+}
+
+func Test_MatchBalancedTags(t *testing.T) {
+	str := `<div class="a"><div>x</div></div> <span>y</span> <DIV/> <div>unclosed <div>z</div>`
+	actualMatch := MatchBalancedTags("div")(str)
+
+	expectedMatch := Match{
+		Template: "%s <span>y</span> %s <div>unclosed %s",
+		Patterns: []string{`<div class="a"><div>x</div></div>`, "<DIV/>", "<div>z</div>"},
+	}
+
+	assert.Equal(t, expectedMatch, actualMatch)
+
+	actualMatch = MatchBalancedTags("div")("<divider>text</divider>")
+	assert.Equal(t, Match{Template: "<divider>text</divider>"}, actualMatch)
+}
+
+func Test_MatchUnknownWords(t *testing.T) {
+	dict := map[string]struct{}{
+		"the": {}, "quick": {}, "fox": {}, "don't": {}, "jump": {}, "well-known": {}, "well": {}, "known": {}, "don": {},
+	}
+	str := "The quick brwn fox don't jump, well-known Fox"
+
+	actualMatch := MatchUnknownWords(dict)(str)
+	expectedMatch := Match{Template: "The quick %s fox don't jump, well-known Fox", Patterns: []string{"brwn"}}
+	assert.Equal(t, expectedMatch, actualMatch)
+
+	actualMatch = MatchUnknownWordParts(dict)(str)
+	expectedMatch = Match{Template: "The quick %s fox don'%s jump, well-known Fox", Patterns: []string{"brwn", "t"}}
+	assert.Equal(t, expectedMatch, actualMatch)
+}
+
+func Test_MatchMultiple(t *testing.T) {
+	str := "abc data company"
+
+	actualMatch := MatchMultiple([]string{"bc", "ab", "data company", "data"})(str)
+	expectedMatch := Match{Template: "%sc %s", Patterns: []string{"ab", "data company"}}
+	assert.Equal(t, expectedMatch, actualMatch)
+	assert.Equal(t, MatchAny(MatchAll("bc"), MatchAll("ab"), MatchAll("data company"), MatchAll("data"))(str), actualMatch)
+
+	actualMatch = MatchMultiple([]string{"data", "data company"})(str)
+	expectedMatch = Match{Template: "abc %s company", Patterns: []string{"data"}}
+	assert.Equal(t, expectedMatch, actualMatch)
+	assert.Equal(t, MatchAny(MatchAll("data"), MatchAll("data company"))(str), actualMatch)
+
+	actualMatch = MatchMultiple([]string{"a.b", "(x"})("axb a.b (x")
+	expectedMatch = Match{Template: "axb %s %s", Patterns: []string{"a.b", "(x"}}
+	assert.Equal(t, expectedMatch, actualMatch)
+
+	assert.Equal(t, Match{Template: str}, MatchMultiple(nil)(str))
+}
+
+func Test_MatchAgainst(t *testing.T) {
+	reference := "it was the best of times, it was the worst of times."
+	str := "Skydome says it was the best of data, or of times at all."
+
+	actualMatch := MatchAgainst(reference, 10)(str)
+	expectedMatch := Match{
+		Template: "Skydome says %sdata, or of times at all.",
+		Patterns: []string{"it was the best of "},
+	}
+	assert.Equal(t, expectedMatch, actualMatch)
+
+	actualMatch = MatchAgainst(reference, 5)(str)
+	expectedMatch = Match{
+		Template: "Skydome says %sdata, or%s at all.",
+		Patterns: []string{"it was the best of ", " of times"},
+	}
+	assert.Equal(t, expectedMatch, actualMatch)
+
+	actualMatch = MatchAgainst("ünïcödé text", 4)("some ünïcödé")
+	expectedMatch = Match{Template: "some %s", Patterns: []string{"ünïcödé"}}
+	assert.Equal(t, expectedMatch, actualMatch)
+}
+
+func Test_MatchTimeZones(t *testing.T) {
+	str := "2023-01-02 15:04 PST, 15:04+05:30, 10:00:00.123Z and 08:00 -0800 but not -0800 or PSTX"
+
+	actualMatch := MatchTimeZones()(str)
+	expectedMatch := Match{
+		Template: "2023-01-02 15:04 %s, 15:04%s, 10:00:00.123%s and 08:00 %s but not -0800 or PSTX",
+		Patterns: []string{"PST", "+05:30", "Z", "-0800"},
+	}
+	assert.Equal(t, expectedMatch, actualMatch)
+
+	actualMatch = MatchTimeZonesWith([]string{"CET"})("12:00 CET, 13:00 PST")
+	expectedMatch = Match{Template: "12:00 %s, 13:00 PST", Patterns: []string{"CET"}}
+	assert.Equal(t, expectedMatch, actualMatch)
+}
+
+func Test_MatchHorizontalWhitespace(t *testing.T) {
+	str := "func main() {  \n\tx :=  1\t \r\n}\n"
+	actualMatch := MatchHorizontalWhitespace()(str)
+
+	expectedMatch := Match{
+		Template: "func%smain()%s{%s\n%sx%s:=%s1%s\r\n}\n",
+		Patterns: []string{" ", " ", "  ", "\t", " ", "  ", "\t "},
+	}
+
+	assert.Equal(t, expectedMatch, actualMatch)
+}
+
+func Test_MatchInitials(t *testing.T) {
+	str := "J.R.R. Tolkien and e.e. cummings met C. S. Lewis, Ph.D."
+
+	actualMatch := MatchInitials()(str)
+	expectedMatch := Match{
+		Template: "%s Tolkien and e.e. cummings met %s Lewis, Ph.D.",
+		Patterns: []string{"J.R.R.", "C. S."},
+	}
+	assert.Equal(t, expectedMatch, actualMatch)
+
+	actualMatch = MatchInitialsAnyCase()(str)
+	expectedMatch = Match{
+		Template: "%s Tolkien and %s cummings met %s Lewis, Ph.D.",
+		Patterns: []string{"J.R.R.", "e.e.", "C. S."},
+	}
+	assert.Equal(t, expectedMatch, actualMatch)
+}
+
+func Test_MatchMarkdownHeaders(t *testing.T) {
+	str := "# Marker\nSee #hashtag\n\n## Installation ##\ntext\n\nUsage\n=====\n\nMatchers\n---\n#NoSpace"
+	actualMatch := MatchMarkdownHeaders()(str)
+
+	expectedMatch := Match{
+		Template: "%s\nSee #hashtag\n\n%s\ntext\n\n%s\n\n%s\n#NoSpace",
+		Patterns: []string{"# Marker", "## Installation ##", "Usage\n=====", "Matchers\n---"},
+		Groups: [][]string{
+			{"1", "Marker"},
+			{"2", "Installation"},
+			{"1", "Usage"},
+			{"2", "Matchers"},
+		},
+	}
+
+	assert.Equal(t, expectedMatch, actualMatch)
+
+	str = "---\n---\n\n- item\n---\n\n1. first\nlazy\n===\n\n***\nA long\n  title\n---"
+	actualMatch = MatchMarkdownHeaders()(str)
+
+	expectedMatch = Match{
+		Template: "---\n---\n\n- item\n---\n\n1. first\nlazy\n===\n\n***\n%s",
+		Patterns: []string{"A long\n  title\n---"},
+		Groups:   [][]string{{"2", "A long\ntitle"}},
+	}
+
+	assert.Equal(t, expectedMatch, actualMatch)
+}
+
+func Test_MatchRepeatedPunctuation(t *testing.T) {
+	str := "really???!! no... ok. fine!"
+	actualMatch := MatchRepeatedPunctuation()(str)
+
+	expectedMatch := Match{
+		Template: "really%s no%s ok. fine!",
+		Patterns: []string{"???!!", "..."},
+	}
+	assert.Equal(t, expectedMatch, actualMatch)
+
+	normalize := func(run string) string {
+		switch {
+		case strings.Contains(run, "?"):
+			return "?"
+		case strings.Contains(run, "!"):
+			return "!"
+		}
+		return "…"
+	}
+	assert.Equal(t, "really? no… ok. fine!", actualMatch.Map(normalize).Render())
+}
+
+func Test_MatchNucleotideRuns(t *testing.T) {
+	str := "The sample at 5% contained gattaca, a TACT and the tactic ACGU."
+
+	actualMatch := MatchNucleotideRuns("ACGT", 4)(str)
+	expectedMatch := Match{
+		Template: "The sample at 5%% contained %s, a %s and the tactic ACGU.",
+		Patterns: []string{"gattaca", "TACT"},
+	}
+	assert.Equal(t, expectedMatch, actualMatch)
+
+	actualMatch = MatchNucleotideRuns("acgu", 5)(str)
+	expectedMatch = Match{Template: "The sample at 5%% contained gattaca, a TACT and the tactic ACGU."}
+	assert.Equal(t, expectedMatch, actualMatch)
+
+	actualMatch = MatchNucleotideRuns("acgu", 4)(str)
+	expectedMatch = Match{
+		Template: "The sample at 5%% contained gattaca, a TACT and the tactic %s.",
+		Patterns: []string{"ACGU"},
+	}
+	assert.Equal(t, expectedMatch, actualMatch)
+}
+
+func Test_MatchTableRows(t *testing.T) {
+	str := "Results:\n| Name | Score |\n|------|:-----:|\n| Ada  | 100   |\n\n+-----+----+\n| a\\|b |    |\n+-----+----+"
+	actualMatch := MatchTableRows()(str)
+
+	expectedMatch := Match{
+		Template: "Results:\n| %s | %s |\n|------|:-----:|\n| %s  | %s   |\n\n+-----+----+\n| %s |    |\n+-----+----+",
+		Patterns: []string{"Name", "Score", "Ada", "100", "a\\|b"},
+		Groups:   [][]string{{"1", "1"}, {"1", "2"}, {"2", "1"}, {"2", "2"}, {"1", "1"}},
+	}
+	assert.Equal(t, expectedMatch, actualMatch)
+
+	assert.Equal(t, Match{Template: "a | b |\n|"}, MatchTableRows()("a | b |\n|"))
+}
+
+func Test_MatchChangelogHeaders(t *testing.T) {
+	str := "# Changelog\n## [Unreleased]\n## [1.2.3] - 2023-01-02\n- fixed 1.2.3 parsing\nv1.2.0 (2022-12-01)  \n## 1.0.0-rc.1\n"
+	actualMatch := MatchChangelogHeaders()(str)
+
+	expectedMatch := Match{
+		Template: "# Changelog\n%s\n%s\n- fixed 1.2.3 parsing\n%s  \n%s\n",
+		Patterns: []string{"## [Unreleased]", "## [1.2.3] - 2023-01-02", "v1.2.0 (2022-12-01)", "## 1.0.0-rc.1"},
+		Groups: [][]string{
+			{"Unreleased", ""},
+			{"1.2.3", "2023-01-02"},
+			{"1.2.0", "2022-12-01"},
+			{"1.0.0-rc.1", ""},
+		},
+	}
+	assert.Equal(t, expectedMatch, actualMatch)
+}
+
+func Test_NormalizeDiacritics(t *testing.T) {
+	str := "Send your résumé to the café, RÉSUMÉ-free resume"
+
+	actualMatch := NormalizeDiacritics(CaseInsensitive(MatchAll("resume")))(str)
+	expectedMatch := Match{
+		Template: "Send your %s to the café, %s-free %s",
+		Patterns: []string{"résumé", "RÉSUMÉ", "resume"},
+	}
+	assert.Equal(t, expectedMatch, actualMatch)
+	assert.Equal(t, [][]int{{10, 18}, {33, 41}, {47, 53}}, actualMatch.Indexes())
+	assert.Equal(t, str, actualMatch.Render())
+
+	actualMatch = NormalizeDiacritics(MatchAll("cafe"))(str)
+	assert.Equal(t, []string{"café"}, actualMatch.Patterns)
+}
+
+func Test_MatchRanges(t *testing.T) {
+	str := "pages 5-10 and 2..8, 1 to 5 or 3–4; not 10-5, -3-4, 2023-01-02, x1-2 or 1.5-2.5%"
+	actualMatch := MatchRanges()(str)
+
+	expectedMatch := Match{
+		Template: "pages %s and %s, %s or %s; not 10-5, -3-4, 2023-01-02, x1-2 or %s%%",
+		Patterns: []string{"5-10", "2..8", "1 to 5", "3–4", "1.5-2.5"},
+		Groups:   [][]string{{"5", "10"}, {"2", "8"}, {"1", "5"}, {"3", "4"}, {"1.5", "2.5"}},
+	}
+	assert.Equal(t, expectedMatch, actualMatch)
+
+	actualMatch = MatchRangesWith([]string{":"})("rows 3:7 and 5-10")
+	expectedMatch = Match{
+		Template: "rows %s and 5-10",
+		Patterns: []string{"3:7"},
+		Groups:   [][]string{{"3", "7"}},
+	}
+	assert.Equal(t, expectedMatch, actualMatch)
+}
+
+func Test_MatchBraceGroups(t *testing.T) {
+	str := "cp img.{jpg,png,gif} log{1..5} {a,{b,c}} {0..10..2} to ${HOME} {single} {} {open"
+	actualMatch := MatchBraceGroups()(str)
+
+	expectedMatch := Match{
+		Template: "cp img.%s log%s %s %s to ${HOME} {single} {} {open",
+		Patterns: []string{"{jpg,png,gif}", "{1..5}", "{a,{b,c}}", "{0..10..2}"},
+		Groups:   [][]string{{"jpg", "png", "gif"}, {"1", "5"}, {"a", "{b,c}"}, {"0", "10", "2"}},
+	}
+	assert.Equal(t, expectedMatch, actualMatch)
+
+	actualMatch = MatchBraceGroups()("{x {a,}} and more")
+	expectedMatch = Match{
+		Template: "{x %s} and more",
+		Patterns: []string{"{a,}"},
+		Groups:   [][]string{{"a", ""}},
+	}
+	assert.Equal(t, expectedMatch, actualMatch)
+}
+
+func Test_withinDistance(t *testing.T) {
+	words := []string{"", "a", "ab", "ba", "abc", "acb", "kitten", "sitting", "sittin", "flaw", "lawn", "abcdef", "azcedf"}
+	distance := func(a, b []rune) int {
+		previous := make([]int, len(b)+1)
+		for j := range previous {
+			previous[j] = j
+		}
+		for i := 1; i <= len(a); i++ {
+			current := make([]int, len(b)+1)
+			current[0] = i
+			for j := 1; j <= len(b); j++ {
+				cost := 1
+				if a[i-1] == b[j-1] {
+					cost = 0
+				}
+				current[j] = min(previous[j-1]+cost, min(previous[j], current[j-1])+1)
+			}
+			previous = current
+		}
+		return previous[len(b)]
+	}
+	for _, a := range words {
+		for _, b := range words {
+			for maxDist := 0; maxDist <= 4; maxDist++ {
+				expected := distance([]rune(a), []rune(b)) <= maxDist
+				assert.Equal(t, expected, withinDistance([]rune(a), []rune(b), maxDist), "%q %q %d", a, b, maxDist)
+			}
+		}
+	}
+}
+
+func Test_MatchFuzzy(t *testing.T) {
+	str := "Colour, color and colorful colr; the collar is cooler"
+
+	actualMatch := MatchFuzzy("color", 1)(str)
+	expectedMatch := Match{
+		Template: "%s, %s and colorful %s; the collar is cooler",
+		Patterns: []string{"Colour", "color", "colr"},
+	}
+	assert.Equal(t, expectedMatch, actualMatch)
+
+	actualMatch = MatchFuzzy("color", 2)(str)
+	expectedMatch = Match{
+		Template: "%s, %s and colorful %s; the %s is %s",
+		Patterns: []string{"Colour", "color", "colr", "collar", "cooler"},
+	}
+	assert.Equal(t, expectedMatch, actualMatch)
+
+	actualMatch = MatchFuzzy("color", 3)(str)
+	assert.Contains(t, actualMatch.Patterns, "colorful")
+
+	actualMatch = MatchFuzzy("color", 0)(str)
+	assert.Equal(t, []string{"color"}, actualMatch.Patterns)
+}
+
+func Test_MatchUnicodeEscapes(t *testing.T) {
+	str := `smile \uD83D\uDE00, caf\u00e9 \U0001F600 but lone \uD83D or \U00110000 \u12`
+	actualMatch := MatchUnicodeEscapes()(str)
+
+	expectedMatch := Match{
+		Template: "smile %s, caf%s %s but lone %s or %s \\u12",
+		Patterns: []string{`\uD83D\uDE00`, `\u00e9`, `\U0001F600`, `\uD83D`, `\U00110000`},
+	}
+	assert.Equal(t, expectedMatch, actualMatch)
+
+	decoded := actualMatch.Map(DecodeUnicodeEscape).Render()
+	assert.Equal(t, "smile \U0001F600, caf\u00e9 \U0001F600 but lone \\uD83D or \\U00110000 \\u12", decoded)
+}
+
+func Test_MatchNumbersLocale(t *testing.T) {
+	str := "paid 1,234,567.89 or 1.234.567,89, then 1,23 and -42.5 at 100 rpm"
+
+	actualMatch := MatchNumbersLocale(',', '.')(str)
+	expectedMatch := Match{
+		Template: "paid %s or %s.%s,%s, then %s,%s and %s at %s rpm",
+		Patterns: []string{"1,234,567.89", "1.234", "567", "89", "1", "23", "-42.5", "100"},
+	}
+	assert.Equal(t, expectedMatch, actualMatch)
+
+	actualMatch = MatchNumbersLocale('.', ',')(str)
+	expectedMatch = Match{
+		Template: "paid %s,%s.%s or %s, then %s and %s.%s at %s rpm",
+		Patterns: []string{"1,234", "567", "89", "1.234.567,89", "1,23", "-42", "5", "100"},
+	}
+	assert.Equal(t, expectedMatch, actualMatch)
+}
+
+func Test_MatchPhoneNumbers(t *testing.T) {
+	str := "Call +1 (555) 123-4567 ext. 890 or 555.123.4567x12, +44 20 7946 0958; not on 2023-01-02 or 12-3456"
+	actualMatch := MatchPhoneNumbers()(str)
+
+	expectedMatch := Match{
+		Template: "Call %s or %s, %s; not on 2023-01-02 or 12-3456",
+		Patterns: []string{"+1 (555) 123-4567 ext. 890", "555.123.4567x12", "+44 20 7946 0958"},
+		Groups: [][]string{
+			{"1", "555", "123-4567", "890"},
+			{"", "555", "123.4567", "12"},
+			{"44", "20", "7946 0958", ""},
+		},
+	}
+	assert.Equal(t, expectedMatch, actualMatch)
+}
+
+func Test_MatchConfusables(t *testing.T) {
+	str := "Log in at раypal.com, PayPal.com or paypa1.com, safe, not paypat.com"
+	actualMatch := MatchConfusables("paypal")(str)
+
+	expectedMatch := Match{
+		Template: "Log in at %s.com, PayPal.com or %s.com, safe, not paypat.com",
+		Patterns: []string{"раypal", "paypa1"},
+	}
+	assert.Equal(t, expectedMatch, actualMatch)
+	assert.Equal(t, [][]int{{10, 18}, {38, 44}}, actualMatch.Indexes())
+}
+
+func Test_MatchPhrase(t *testing.T) {
+	str := "New\n  York, New York\tcity; a New Yorker, renew York"
+	actualMatch := MatchPhrase("New", "York")(str)
+
+	expectedMatch := Match{
+		Template: "%s, %s\tcity; a New Yorker, renew York",
+		Patterns: []string{"New\n  York", "New York"},
+	}
+	assert.Equal(t, expectedMatch, actualMatch)
+
+	actualMatch = MatchPhrase("a", "New")(str)
+	expectedMatch = Match{
+		Template: "New\n  York, New York\tcity; %s Yorker, renew York",
+		Patterns: []string{"a New"},
+	}
+	assert.Equal(t, expectedMatch, actualMatch)
+}
+
+func Test_MatchScientific(t *testing.T) {
+	str := "value 6.022E23 at 2.5, -1.23e-4 or 3.14, all of 1e9; not 0x1e5 or 2e"
+	actualMatch := MatchScientific()(str)
+
+	expectedMatch := Match{
+		Template: "value %s at 2.5, %s or 3.14, all of %s; not 0x1e5 or 2e",
+		Patterns: []string{"6.022E23", "-1.23e-4", "1e9"},
+		Groups:   [][]string{{"6.022", "23"}, {"-1.23", "-4"}, {"1", "9"}},
+	}
+	assert.Equal(t, expectedMatch, actualMatch)
+}
+
+func Test_MatchLogLevels(t *testing.T) {
+	str := "INFO starting... ERROR failed, Warning: full disk; information is not a level [warn]"
+	actualMatch := MatchLogLevels()(str)
+
+	expectedMatch := Match{
+		Template: "%s starting... %s failed, %s: full disk; information is not a level [%s]",
+		Patterns: []string{"INFO", "ERROR", "Warning", "warn"},
+		Labels:   []string{"info", "error", "warn", "warn"},
+	}
+	assert.Equal(t, expectedMatch, actualMatch)
+
+	actualMatch = MatchLogLevelsWith(map[string]string{"E": "error", "I": "info"})("I0102 started, E0102 failed: E")
+	expectedMatch = Match{
+		Template: "I0102 started, E0102 failed: %s",
+		Patterns: []string{"E"},
+		Labels:   []string{"error"},
+	}
+	assert.Equal(t, expectedMatch, actualMatch)
+}
+
+func Test_MatchContractions(t *testing.T) {
+	str := "Don't worry, we’re sure its owner's fine and y'all WON'T mind"
+	actualMatch := MatchContractions()(str)
+
+	expectedMatch := Match{
+		Template: "%s worry, %s sure its owner's fine and %s %s mind",
+		Patterns: []string{"Don't", "we’re", "y'all", "WON'T"},
+		Groups:   [][]string{{"Do not"}, {"we are"}, {"you all"}, {"WILL NOT"}},
+	}
+	assert.Equal(t, expectedMatch, actualMatch)
+
+	expanded := actualMatch.Map(ExpandContraction).Render()
+	assert.Equal(t, "Do not worry, we are sure its owner's fine and you all WILL NOT mind", expanded)
+}
+
+func Test_MatchRepeated(t *testing.T) {
+	str := "abababX ab abab, then aaaaa"
+
+	actualMatch := MatchRepeated("ab", 2)(str)
+	expectedMatch := Match{
+		Template: "%sX ab %s, then aaaaa",
+		Patterns: []string{"ababab", "abab"},
+		Counts:   []int{3, 2},
+	}
+	assert.Equal(t, expectedMatch, actualMatch)
+
+	actualMatch = MatchRepeated("aa", 2)(str)
+	expectedMatch = Match{
+		Template: "abababX ab abab, then %sa",
+		Patterns: []string{"aaaa"},
+		Counts:   []int{2},
+	}
+	assert.Equal(t, expectedMatch, actualMatch)
+
+	assert.Equal(t, Match{Template: "aaa"}, MatchRepeated("aa", 2)("aaa"))
+}
+
+func Test_MatchSPDX(t *testing.T) {
+	str := "SPDX-License-Identifier: MIT OR Apache-2.0, also GPL-2.0+ WITH Classpath-exception-2.0 and Foo-1.0 or mit; MIT AND Foo"
+	actualMatch := MatchSPDX()(str)
+
+	expectedMatch := Match{
+		Template: "SPDX-License-Identifier: %s, also %s and Foo-1.0 or %s; %s AND Foo",
+		Patterns: []string{"MIT OR Apache-2.0", "GPL-2.0+ WITH Classpath-exception-2.0", "mit", "MIT"},
+	}
+	assert.Equal(t, expectedMatch, actualMatch)
+
+	actualMatch = MatchSPDXWith([]string{"Foo-1.0"}, nil)(str)
+	expectedMatch = Match{
+		Template: "SPDX-License-Identifier: MIT OR Apache-2.0, also GPL-2.0+ WITH Classpath-exception-2.0 and %s or mit; MIT AND Foo",
+		Patterns: []string{"Foo-1.0"},
+	}
+	assert.Equal(t, expectedMatch, actualMatch)
+}
+
+func Test_MatchRepeatedNgrams(t *testing.T) {
+	str := "All rights reserved. Text. all rights reserved, more; All Rights Reserved"
+	actualMatch := MatchRepeatedNgrams(3, 3)(str)
+
+	expectedMatch := Match{
+		Template: "%s. Text. %s, more; %s",
+		Patterns: []string{"All rights reserved", "all rights reserved", "All Rights Reserved"},
+	}
+	assert.Equal(t, expectedMatch, actualMatch)
+
+	actualMatch = MatchRepeatedNgrams(2, 2)("a b a b a b c")
+	expectedMatch = Match{
+		Template: "%s c",
+		Patterns: []string{"a b a b a b"},
+	}
+	assert.Equal(t, expectedMatch, actualMatch)
+
+	assert.Equal(t, Match{Template: "a b"}, MatchRepeatedNgrams(3, 1)("a b"))
+}
+
+func Test_MatchPostalCode(t *testing.T) {
+	us := "Ship to Austin, TX 78701-1234 or 10001; sure"
+	uk := "London SW1A 1AA or M1 1AE"
+	ca := "Ottawa ON K1A 0B1"
+
+	assert.Equal(t, []string{"78701-1234", "10001"}, MatchPostalCode("US")(us).Patterns)
+	assert.Equal(t, "Ship to Austin, TX %s or %s; sure", MatchPostalCode("us")(us).Template)
+	assert.Equal(t, []string{"SW1A 1AA", "M1 1AE"}, MatchPostalCode("UK")(uk).Patterns)
+	assert.Equal(t, []string{"K1A 0B1"}, MatchPostalCode("CA")(ca).Patterns)
+
+	assert.Empty(t, MatchPostalCode("UK")(us).Patterns)
+	assert.Empty(t, MatchPostalCode("CA")(uk).Patterns)
+	assert.Empty(t, MatchPostalCode("US")(ca).Patterns)
+	assert.Equal(t, Match{Template: "zip 12345"}, MatchPostalCode("XX")("zip 12345"))
+
+	actualMatch := MatchPostalCodeAny()(us + "; " + uk + "; " + ca)
+	assert.Equal(t, []string{"78701-1234", "10001", "SW1A 1AA", "M1 1AE", "K1A 0B1"}, actualMatch.Patterns)
+	assert.Equal(t, []string{"US", "US", "UK", "UK", "CA"}, actualMatch.Labels)
+}
+
+func Test_MatchDataURIs(t *testing.T) {
+	str := `<img src="data:image/png;base64,iVBORw0KGgo=">, url(data:text/plain;charset=utf-8,100%25%20sure) or data:,hi`
+	actualMatch := MatchDataURIs()(str)
+
+	expectedMatch := Match{
+		Template: `<img src="%s">, url(%s) or %s`,
+		Patterns: []string{"data:image/png;base64,iVBORw0KGgo=", "data:text/plain;charset=utf-8,100%25%20sure", "data:,hi"},
+		Groups: [][]string{
+			{"image/png", "base64", "iVBORw0KGgo="},
+			{"text/plain;charset=utf-8", "", "100%25%20sure"},
+			{"", "", "hi"},
+		},
+	}
+	assert.Equal(t, expectedMatch, actualMatch)
+
+	actualMatch = MaxPatternLength(MatchDataURIs(), 20)(str)
+	assert.Equal(t, []string{"data:,hi"}, actualMatch.Patterns)
+	assert.Equal(t, [][]string{{"", "", "hi"}}, actualMatch.Groups)
+}
+
+func Test_MatchWikiLinks(t *testing.T) {
+	str := "See [[Home]], [[Help:Index|the help]][[Notes [draft]|notes [1]]] and [not a link], [[broken"
+	actualMatch := MatchWikiLinks()(str)
+
+	expectedMatch := Match{
+		Template: "See %s, %s%s and [not a link], [[broken",
+		Patterns: []string{"[[Home]]", "[[Help:Index|the help]]", "[[Notes [draft]|notes [1]]]"},
+		Groups:   [][]string{{"Home", ""}, {"Help:Index", "the help"}, {"Notes [draft]", "notes [1]"}},
+	}
+	assert.Equal(t, expectedMatch, actualMatch)
+}
+
+func Test_MatchSSN(t *testing.T) {
+	str := "SSN 123-45-6789, 666-12-3456, 123456789, 123-00-4567 or 1234567890"
+
+	actualMatch := MatchSSN()(str)
+	expectedMatch := Match{
+		Template: "SSN %s, 666-12-3456, %s, 123-00-4567 or 1234567890",
+		Patterns: []string{"123-45-6789", "123456789"},
+	}
+	assert.Equal(t, expectedMatch, actualMatch)
+
+	actualMatch = MatchSSNLoose()(str)
+	expectedMatch = Match{
+		Template: "SSN %s, %s, %s, %s or 1234567890",
+		Patterns: []string{"123-45-6789", "666-12-3456", "123456789", "123-00-4567"},
+	}
+	assert.Equal(t, expectedMatch, actualMatch)
+}
+
+func Test_MatchICUPlaceholders(t *testing.T) {
+	str := "Hi {name}, you have {count, plural, one {# item} other {# items}} in '{braces}' { not one } {open"
+	actualMatch := MatchICUPlaceholders()(str)
+
+	expectedMatch := Match{
+		Template: "Hi %s, you have %s in '{braces}' { not one } {open",
+		Patterns: []string{"{name}", "{count, plural, one {# item} other {# items}}"},
+	}
+	assert.Equal(t, expectedMatch, actualMatch)
+}
+
+func Test_MatchTripledChars(t *testing.T) {
+	str := "sooo goood, yesss cool!!!! ééé"
+	actualMatch := MatchTripledChars()(str)
+
+	expectedMatch := Match{
+		Template: "s%s g%sd, ye%s cool%s %s",
+		Patterns: []string{"ooo", "ooo", "sss", "!!!!", "ééé"},
+	}
+	assert.Equal(t, expectedMatch, actualMatch)
+
+	assert.Equal(t, "soo good, yess cool!! éé", actualMatch.Map(ReduceRun(2)).Render())
+	assert.Equal(t, "so god, yes cool! é", actualMatch.Map(ReduceRun(1)).Render())
+
+	actualMatch = MatchCharRuns(2)("aab\x00\x00")
+	assert.Equal(t, []string{"aa", "\x00\x00"}, actualMatch.Patterns)
+}
+
+func Test_MatchWordsByFrequency(t *testing.T) {
+	str := strings.Repeat("data is data. ", 5) + "Skydome: DATA"
+	rare, common := MatchWordsByFrequency(str, 1, 10)
+
+	actualMatch := rare(str)
+	assert.Equal(t, []string{"Skydome"}, actualMatch.Patterns)
+	assert.Equal(t, strings.Repeat("data is data. ", 5)+"%s: DATA", actualMatch.Template)
+
+	actualMatch = common(str)
+	assert.Len(t, actualMatch.Patterns, 11)
+	assert.Equal(t, "DATA", actualMatch.Patterns[10])
+
+	assert.Equal(t, []string{"Marker"}, rare("Marker is data").Patterns)
+	assert.Equal(t, []string{"data"}, common("Marker is data").Patterns)
+}
+
+func Test_MatchArrows(t *testing.T) {
+	str := "a --> b <- c, x<->y => z ==> but not ---> or >"
+	expectedMatch := Match{
+		Template: "a %s b %s c, x%sy %s z %s but not ---> or >",
+		Patterns: []string{"-->", "<-", "<->", "=>", "==>"},
+	}
+	assert.Equal(t, expectedMatch, MatchArrows()(str))
+
+	expectedMatch = Match{Template: "a %s b <- c %s d", Patterns: []string{"~>", "~~>"}}
+	assert.Equal(t, expectedMatch, MatchArrowsWith([]string{"~>", "~~>"})("a ~> b <- c ~~> d"))
+}
+
+func Test_MatchHandlebars(t *testing.T) {
+	str := "{{! greeting }}{{#if user}}{{#each items}}{{name}}{{/each}}{{{raw}}}{{/if}} {{#with x}}"
+	actualMatch := MatchHandlebars()(str)
+
+	expectedMatch := Match{
+		Template: "%s%s%s%s%s%s%s {{#with x}}",
+		Patterns: []string{"{{! greeting }}", "{{#if user}}", "{{#each items}}", "{{name}}", "{{/each}}", "{{{raw}}}", "{{/if}}"},
+		Labels:   []string{"comment", "block", "block", "expression", "block", "expression", "block"},
+		Depths:   []int{0, 0, 1, 2, 1, 1, 0},
+	}
+	assert.Equal(t, expectedMatch, actualMatch)
+
+	actualMatch = MatchHandlebars()("{{!-- {{#if}} --}}{{#if a}}{{/each}}{{/if}}")
+	expectedMatch = Match{
+		Template: "%s%s{{/each}}%s",
+		Patterns: []string{"{{!-- {{#if}} --}}", "{{#if a}}", "{{/if}}"},
+		Labels:   []string{"comment", "block", "block"},
+		Depths:   []int{0, 0, 0},
+	}
+	assert.Equal(t, expectedMatch, actualMatch)
+
+	assert.Equal(t, Match{Template: "no tags"}, MatchHandlebars()("no tags"))
+}
@@ -61,3 +61,126 @@ var timestampLayoutRegexps = map[string]*regexp.Regexp{
 
 // EmailRegexp is a Regular expression for RFC5322
 var EmailRegexp = regexp.MustCompile(`[a-z0-9!#$%&'*+/=?^_{|}~-]+(?:\.[a-z0-9!#$%&'*+/=?^_{|}~-]+)*@(?:[a-z0-9](?:[a-z0-9-]*[a-z0-9])?\.)+[a-z0-9](?:[a-z0-9-]*[a-z0-9])`)
+
+// DiffHeaderRegexp is a Regular expression for unified diff hunk headers and consecutive ---/+++ file header lines
+var DiffHeaderRegexp = regexp.MustCompile(`(?m)^(?:@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@|(--- (\S+)[^\n]*)\n(\+\+\+ (\S+)[^\n]*))`)
+
+// NumberRegexp is a Regular expression for signed integers, decimals and numbers in exponent notation
+var NumberRegexp = regexp.MustCompile(`[-+]?(?:\d+(?:\.\d+)?|\.\d+)(?:[eE][-+]?\d+)?`)
+
+// Regular expressions for template placeholders, %% is included in PrintfPlaceholderRegexp to skip escaped percent signs
+var (
+	PrintfPlaceholderRegexp     = regexp.MustCompile(`%%|%(?:\[\d+\])?[-+# 0]*(?:\d+|\*)?(?:\.(?:\d+|\*))?[a-zA-Z]`)
+	GoTemplatePlaceholderRegexp = regexp.MustCompile(`\{\{[^{}]*\}\}`)
+	ShellPlaceholderRegexp      = regexp.MustCompile(`\$\{[A-Za-z_][A-Za-z0-9_]*\}`)
+)
+
+// ShortcodeTagRegexp is a Regular expression for BBCode-style opening and closing tags
+var ShortcodeTagRegexp = regexp.MustCompile(`\[(/?)([a-zA-Z][a-zA-Z0-9]*)([^\[\]]*)\]`)
+
+// IdentifierRegexp is a Regular expression for ASCII alphanumeric identifiers starting with a letter
+var IdentifierRegexp = regexp.MustCompile(`\b[A-Za-z][A-Za-z0-9]*\b`)
+
+const (
+	goDuration    = `\b(?:[0-9]+(?:\.[0-9]+)?(?:ns|us|µs|μs|ms|s|m|h))+\b`
+	humanDuration = `(?i)\b[0-9]+(?:\.[0-9]+)? ?(?:nanoseconds?|microseconds?|milliseconds?|seconds?|secs?|minutes?|mins?|hours?|hrs?|days?|weeks?)\b`
+)
+
+// Regular expressions for durations
+var (
+	GoDurationRegexp = regexp.MustCompile(goDuration)
+	DurationRegexp   = regexp.MustCompile(fmt.Sprintf("%s|%s", goDuration, humanDuration))
+)
+
+// ListMarkerRegexp is a Regular expression for markdown list markers with their indentation at the start of lines
+var ListMarkerRegexp = regexp.MustCompile(`(?m)^([ \t]*)([0-9]+[.)]|[-*+])[ \t]`)
+
+// GitRefRegexp is a Regular expression for fully qualified git references
+var GitRefRegexp = regexp.MustCompile(`\brefs(?:/[A-Za-z0-9_-](?:[A-Za-z0-9._-]*[A-Za-z0-9_-])?)+`)
+
+// FractionRegexp is a Regular expression for slash fractions and Unicode vulgar fractions
+var FractionRegexp = regexp.MustCompile(`\b([0-9]+)[/⁄]([0-9]+)\b|[¼½¾⅐⅑⅒⅓⅔⅕⅖⅗⅘⅙⅚⅛⅜⅝⅞↉]`)
+
+// DomainRegexp is a Regular expression for domain names with at least two labels and an alphabetic last label
+var DomainRegexp = regexp.MustCompile(`\b(?:[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?\.)+[a-zA-Z]{2,63}\b`)
+
+// HTMLEntityRegexp is a Regular expression for named, decimal and hexadecimal HTML character references
+var HTMLEntityRegexp = regexp.MustCompile(`&(?:[a-zA-Z][a-zA-Z0-9]{1,31}|#[0-9]{1,7}|#[xX][0-9a-fA-F]{1,6});`)
+
+// WordRegexp is a Regular expression for words including contractions and hyphenated words
+var WordRegexp = regexp.MustCompile(`\pL+(?:['’-]\pL+)*`)
+
+// TimeZoneOffsetRegexp is a Regular expression for times followed by a numeric time zone offset or Z, the offset is
+// captured in the first group
+var TimeZoneOffsetRegexp = regexp.MustCompile(`\b[0-9]{1,2}:[0-9]{2}(?::[0-9]{2}(?:\.[0-9]+)?)? ?(Z|[+-](?:[0-9]{2}:[0-9]{2}|[0-9]{4}))\b`)
+
+// Regular expressions for initials, optionally separated by a space
+var (
+	UpperInitialsRegexp = regexp.MustCompile(`\p{Lu}\.(?: ?\p{Lu}\.)*`)
+	InitialsRegexp      = regexp.MustCompile(`\pL\.(?: ?\pL\.)*`)
+)
+
+// Regular expressions for markdown headers, capturing the level marker and the title
+var (
+	ATXHeaderRegexp    = regexp.MustCompile(`(?m)^ {0,3}(#{1,6})[ \t]+([^\n]*?)(?:[ \t]+#+)?[ \t]*$`)
+	SetextHeaderRegexp = regexp.MustCompile(`(?m)^ {0,3}([^#\s][^\n]*)\n {0,3}(=+|-+)[ \t]*$`)
+)
+
+// ThematicBreakRegexp is a Regular expression for markdown thematic break lines like ---, * * * or ___
+var ThematicBreakRegexp = regexp.MustCompile(`^ {0,3}(?:(?:-[ \t]*){3,}|(?:\*[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+
+// RepeatedPunctuationRegexp is a Regular expression for runs of repeated sentence punctuation
+var RepeatedPunctuationRegexp = regexp.MustCompile(`[!?.]{2,}`)
+
+// Regular expressions for the separator cells and border lines of markdown and ASCII tables
+var (
+	TableSeparatorCellRegexp = regexp.MustCompile(`^[-:+=]*[-=][-:+=]*$`)
+	TableBorderRegexp        = regexp.MustCompile(`^\s*\+[-=+]*[-=][-=+]*\+\s*$`)
+)
+
+// ChangelogHeaderRegexp is a Regular expression for changelog release header lines, capturing the version and the date
+var ChangelogHeaderRegexp = regexp.MustCompile(`(?m)^(?:#{1,6}[ \t]+)?(?:\[(?:v?(\d+\.\d+\.\d+(?:-[0-9A-Za-z.-]+)?(?:\+[0-9A-Za-z.-]+)?)|((?i:unreleased)))\]|v?(\d+\.\d+\.\d+(?:-[0-9A-Za-z.-]+)?(?:\+[0-9A-Za-z.-]+)?))(?:[ \t]+(?:-[ \t]+(\d{4}-\d{2}-\d{2})|\((\d{4}-\d{2}-\d{2})\)))?[ \t]*$`)
+
+// BraceRangeRegexp is a Regular expression for the content of shell brace expansion ranges like 1..5, a..e or 0..10..2,
+// capturing the bounds and the increment
+var BraceRangeRegexp = regexp.MustCompile(`^(-?\d+|[a-zA-Z])\.\.(-?\d+|[a-zA-Z])(?:\.\.(-?\d+))?$`)
+
+// UnicodeEscapeRegexp is a Regular expression for \uXXXX escapes, including UTF-16 surrogate pairs, and \UXXXXXXXX escapes
+var UnicodeEscapeRegexp = regexp.MustCompile(`\\u[dD][89abAB][0-9a-fA-F]{2}\\u[dD][c-fC-F][0-9a-fA-F]{2}|\\u[0-9a-fA-F]{4}|\\U[0-9a-fA-F]{8}`)
+
+// PhoneRegexp is a Regular expression for phone numbers, capturing the country code, the area code in or out of
+// parentheses, the subscriber number and the extension
+var PhoneRegexp = regexp.MustCompile(`(?:\+(\d{1,3})[ .-]?)?(?:\((\d{1,4})\)[ .-]?|(\d{1,4})[ .-])(\d{3,4}[ .-]?\d{4})(?:[ \t]*(?:x|ext\.?|extension)[ \t]*(\d{1,6}))?`)
+
+// ScientificNumberRegexp is a Regular expression for numbers in scientific notation, capturing the mantissa and the
+// exponent
+var ScientificNumberRegexp = regexp.MustCompile(`([-+]?(?:\d+(?:\.\d*)?|\.\d+))[eE]([-+]?\d+)`)
+
+// SPDXTokenRegexp is a Regular expression for the identifiers and operators of SPDX license expressions
+var SPDXTokenRegexp = regexp.MustCompile(`[A-Za-z0-9](?:[A-Za-z0-9.+-]*[A-Za-z0-9+])?`)
+
+// PostalCodeRegexps are Regular expressions for the postal codes of the countries supported by MatchPostalCode
+var PostalCodeRegexps = map[string]*regexp.Regexp{
+	"CA": regexp.MustCompile(`\b[ABCEGHJ-NPRSTVXY]\d[ABCEGHJ-NPRSTV-Z] ?\d[ABCEGHJ-NPRSTV-Z]\d\b`),
+	"UK": regexp.MustCompile(`\b(?:[A-Z]{1,2}\d[A-Z\d]? ?\d[ABD-HJLNP-UW-Z]{2}|GIR ?0AA)\b`),
+	"US": regexp.MustCompile(`\b\d{5}(?:-\d{4})?\b`),
+}
+
+// DataURIRegexp is a Regular expression for data URIs, capturing the media type with its parameters, the base64 marker
+// and the payload
+var DataURIRegexp = regexp.MustCompile(`\bdata:([\w!#$&^.+-]+/[\w!#$&^.+-]+(?:;[\w!#$&^.+-]+=[\w!#$&^.+-]+)*)?(;base64)?,([^\s"'<>()]*)`)
+
+// WikiLinkRegexp is a Regular expression for [[Target]] and [[Target|Display]] wiki links, capturing the target and the
+// display label. Single brackets are allowed inside.
+var WikiLinkRegexp = regexp.MustCompile(`\[\[((?:[^\[\]|\n]|\[[^\[\]\n]*\])+)(?:\|((?:[^\[\]\n]|\[[^\[\]\n]*\])*))?\]\]`)
+
+// SSNRegexp is a Regular expression for US social security numbers in 123-45-6789 or 123456789 form, capturing the
+// area, group and serial numbers of either form
+var SSNRegexp = regexp.MustCompile(`\b(?:(\d{3})-(\d{2})-(\d{4})|(\d{3})(\d{2})(\d{4}))\b`)
+
+// ICUArgumentRegexp is a Regular expression for the start of ICU MessageFormat placeholders
+var ICUArgumentRegexp = regexp.MustCompile(`^\{\s*\w+\s*[,}]`)
+
+// HandlebarsTagRegexp is a Regular expression for Handlebars comments, raw and escaped expressions and block tags,
+// capturing the # or / of block tags and the block helper name
+var HandlebarsTagRegexp = regexp.MustCompile(`\{\{~?!--[\s\S]*?--~?\}\}|\{\{~?![\s\S]*?\}\}|\{\{\{[^{}]*\}\}\}|\{\{~?\s*([#/])\s*([^\s{}~]+)[^{}]*\}\}|\{\{[^{}]*\}\}`)